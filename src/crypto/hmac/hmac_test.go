@@ -591,6 +591,20 @@ func TestEqual(t *testing.T) {
 	}
 }
 
+func TestSelfTest(t *testing.T) {
+	if err := SelfTest(); err != nil {
+		t.Fatalf("SelfTest() = %v", err)
+	}
+
+	// A wrong known answer must be reported.
+	saved := selfTests[0].out
+	defer func() { selfTests[0].out = saved }()
+	selfTests[0].out = "\x00" + saved[1:]
+	if err := SelfTest(); err == nil {
+		t.Error("SelfTest() with a corrupted known answer = nil")
+	}
+}
+
 func BenchmarkHMACSHA256_1K(b *testing.B) {
 	key := make([]byte, 32)
 	buf := make([]byte, 1024)
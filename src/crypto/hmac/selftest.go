@@ -0,0 +1,130 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hmac
+
+import (
+	"crypto"
+	"errors"
+	"strings"
+)
+
+// selfTests are known-answer tests from RFC 2202, test cases 2 and 6, for
+// MD5 and SHA-1, and from RFC 4231, test cases 2 and 6, for SHA-2.
+var selfTests = []struct {
+	name string
+	hash crypto.Hash
+	key  string
+	in   string
+	out  string
+}{
+	{
+		"HMAC-MD5 short key", crypto.MD5,
+		"Jefe",
+		"what do ya want for nothing?",
+		"\x75\x0c\x78\x3e\x6a\xb0\xb5\x03\xea\xa8\x6e\x31\x0a\x5d\xb7\x38",
+	},
+	{
+		"HMAC-SHA-1 short key", crypto.SHA1,
+		"Jefe",
+		"what do ya want for nothing?",
+		"\xef\xfc\xdf\x6a\xe5\xeb\x2f\xa2\xd2\x74\x16\xd5\xf1\x84\xdf\x9c" +
+			"\x25\x9a\x7c\x79",
+	},
+	{
+		"HMAC-SHA-224 short key", crypto.SHA224,
+		"Jefe",
+		"what do ya want for nothing?",
+		"\xa3\x0e\x01\x09\x8b\xc6\xdb\xbf\x45\x69\x0f\x3a\x7e\x9e\x6d\x0f" +
+			"\x8b\xbe\xa2\xa3\x9e\x61\x48\x00\x8f\xd0\x5e\x44",
+	},
+	{
+		"HMAC-SHA-256 short key", crypto.SHA256,
+		"Jefe",
+		"what do ya want for nothing?",
+		"\x5b\xdc\xc1\x46\xbf\x60\x75\x4e\x6a\x04\x24\x26\x08\x95\x75\xc7" +
+			"\x5a\x00\x3f\x08\x9d\x27\x39\x83\x9d\xec\x58\xb9\x64\xec\x38\x43",
+	},
+	{
+		"HMAC-SHA-384 short key", crypto.SHA384,
+		"Jefe",
+		"what do ya want for nothing?",
+		"\xaf\x45\xd2\xe3\x76\x48\x40\x31\x61\x7f\x78\xd2\xb5\x8a\x6b\x1b" +
+			"\x9c\x7e\xf4\x64\xf5\xa0\x1b\x47\xe4\x2e\xc3\x73\x63\x22\x44\x5e" +
+			"\x8e\x22\x40\xca\x5e\x69\xe2\xc7\x8b\x32\x39\xec\xfa\xb2\x16\x49",
+	},
+	{
+		"HMAC-SHA-512 short key", crypto.SHA512,
+		"Jefe",
+		"what do ya want for nothing?",
+		"\x16\x4b\x7a\x7b\xfc\xf8\x19\xe2\xe3\x95\xfb\xe7\x3b\x56\xe0\xa3" +
+			"\x87\xbd\x64\x22\x2e\x83\x1f\xd6\x10\x27\x0c\xd7\xea\x25\x05\x54" +
+			"\x97\x58\xbf\x75\xc0\x5a\x99\x4a\x6d\x03\x4f\x65\xf8\xf0\xe6\xfd" +
+			"\xca\xea\xb1\xa3\x4d\x4a\x6b\x4b\x63\x6e\x07\x0a\x38\xbc\xe7\x37",
+	},
+	{
+		"HMAC-MD5 long key", crypto.MD5,
+		strings.Repeat("\xaa", 80),
+		"Test Using Larger Than Block-Size Key - Hash Key First",
+		"\x6b\x1a\xb7\xfe\x4b\xd7\xbf\x8f\x0b\x62\xe6\xce\x61\xb9\xd0\xcd",
+	},
+	{
+		"HMAC-SHA-1 long key", crypto.SHA1,
+		strings.Repeat("\xaa", 80),
+		"Test Using Larger Than Block-Size Key - Hash Key First",
+		"\xaa\x4a\xe5\xe1\x52\x72\xd0\x0e\x95\x70\x56\x37\xce\x8a\x3b\x55" +
+			"\xed\x40\x21\x12",
+	},
+	{
+		"HMAC-SHA-224 long key", crypto.SHA224,
+		strings.Repeat("\xaa", 131),
+		"Test Using Larger Than Block-Size Key - Hash Key First",
+		"\x95\xe9\xa0\xdb\x96\x20\x95\xad\xae\xbe\x9b\x2d\x6f\x0d\xbc\xe2" +
+			"\xd4\x99\xf1\x12\xf2\xd2\xb7\x27\x3f\xa6\x87\x0e",
+	},
+	{
+		"HMAC-SHA-256 long key", crypto.SHA256,
+		strings.Repeat("\xaa", 131),
+		"Test Using Larger Than Block-Size Key - Hash Key First",
+		"\x60\xe4\x31\x59\x1e\xe0\xb6\x7f\x0d\x8a\x26\xaa\xcb\xf5\xb7\x7f" +
+			"\x8e\x0b\xc6\x21\x37\x28\xc5\x14\x05\x46\x04\x0f\x0e\xe3\x7f\x54",
+	},
+	{
+		"HMAC-SHA-384 long key", crypto.SHA384,
+		strings.Repeat("\xaa", 131),
+		"Test Using Larger Than Block-Size Key - Hash Key First",
+		"\x4e\xce\x08\x44\x85\x81\x3e\x90\x88\xd2\xc6\x3a\x04\x1b\xc5\xb4" +
+			"\x4f\x9e\xf1\x01\x2a\x2b\x58\x8f\x3c\xd1\x1f\x05\x03\x3a\xc4\xc6" +
+			"\x0c\x2e\xf6\xab\x40\x30\xfe\x82\x96\x24\x8d\xf1\x63\xf4\x49\x52",
+	},
+	{
+		"HMAC-SHA-512 long key", crypto.SHA512,
+		strings.Repeat("\xaa", 131),
+		"Test Using Larger Than Block-Size Key - Hash Key First",
+		"\x80\xb2\x42\x63\xc7\xc1\xa3\xeb\xb7\x14\x93\xc1\xdd\x7b\xe8\xb4" +
+			"\x9b\x46\xd1\xf4\x1b\x4a\xee\xc1\x12\x1b\x01\x37\x83\xf8\xf3\x52" +
+			"\x6b\x56\xd0\x37\xe0\x5f\x25\x98\xbd\x0f\xd2\x21\x5d\x6a\x1e\x52" +
+			"\x95\xe6\x4f\x73\xf6\x3f\x0a\xec\x8b\x91\x5a\x98\x5d\x78\x65\x98",
+	},
+}
+
+// SelfTest runs known-answer tests of HMAC with MD5, SHA-1, SHA-224,
+// SHA-256, SHA-384 and SHA-512, with keys shorter and longer than the
+// block size. This package does not depend on any hash function, so only
+// the hash functions linked into the binary, as reported by
+// crypto.Hash.Available, are tested. SelfTest returns an error naming the
+// first test that fails.
+func SelfTest() error {
+	for _, tt := range selfTests {
+		if !tt.hash.Available() {
+			continue
+		}
+		h := New(tt.hash.New, []byte(tt.key))
+		h.Write([]byte(tt.in))
+		if string(h.Sum(nil)) != tt.out {
+			return errors.New("crypto/hmac: self-test failed: " + tt.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package hashenc implements the encodings that crypto/md5 and
+// crypto/sha256 share: hexadecimal text, the QR alphanumeric codes of
+// their SumCode functions and the padding that ends a message. They live
+// here because the crypto packages may not import encoding/hex.
+package hashenc // import "crypto/internal/hashenc"
+
+import "encoding/binary"
+
+const hextable = "0123456789abcdef"
+
+// EncodeHex writes the lowercase hexadecimal encoding of src to dst, which
+// must be at least 2*len(src) bytes long.
+func EncodeHex(dst, src []byte) {
+	for i, v := range src {
+		dst[2*i] = hextable[v>>4]
+		dst[2*i+1] = hextable[v&0x0f]
+	}
+}
+
+// DecodeHex returns the bytes encoded in hexadecimal, of either case, by
+// src. It reports false if src has odd length or holds a character that
+// is not a hexadecimal digit.
+func DecodeHex(src []byte) ([]byte, bool) {
+	if len(src)%2 != 0 {
+		return nil, false
+	}
+	b := make([]byte, len(src)/2)
+	for i := range b {
+		hi, ok1 := fromHexChar(src[2*i])
+		lo, ok2 := fromHexChar(src[2*i+1])
+		if !ok1 || !ok2 {
+			return nil, false
+		}
+		b[i] = hi<<4 | lo
+	}
+	return b, true
+}
+
+// fromHexChar converts a hex character into its value and a success flag.
+func fromHexChar(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// codeAlphabet is the QR code alphanumeric mode character set.
+const codeAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// EncodeCode returns the length least significant base-45 digits of the
+// big-endian integer n, written with the QR code alphanumeric mode
+// characters. It overwrites n.
+func EncodeCode(n []byte, length int) string {
+	code := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		// Divide n by 45 in place, keeping the remainder.
+		var rem uint
+		for j := range n {
+			cur := rem<<8 | uint(n[j])
+			n[j] = byte(cur / 45)
+			rem = cur % 45
+		}
+		code[i] = codeAlphabet[rem]
+	}
+	return string(code)
+}
+
+// Pad builds the final one or two 64-byte blocks of a message of length
+// bytes in buf and returns them. rest holds the message bytes after the
+// last full block and must be shorter than 64 bytes. The length in bits
+// is stored in the byte order of the hash: little-endian for MD5 and
+// big-endian for SHA256.
+func Pad(buf *[128]byte, rest []byte, length uint64, order binary.ByteOrder) []byte {
+	m := copy(buf[:], rest)
+	size := 64
+	if m >= 56 {
+		size = 128
+	}
+	buf[m] = 0x80
+	for i := m + 1; i < size-8; i++ {
+		buf[i] = 0
+	}
+	order.PutUint64(buf[size-8:], length<<3)
+	return buf[:size]
+}
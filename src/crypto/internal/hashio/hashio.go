@@ -0,0 +1,135 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package hashio implements the read loops behind the streaming helpers
+// of crypto/md5 and crypto/sha256, such as SumFile and SumSplit, so that
+// the two packages share one copy of them.
+package hashio // import "crypto/internal/hashio"
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"time"
+)
+
+// blockSize is the block size of MD5 and SHA256.
+const blockSize = 64
+
+// BufSize is the size of the buffer the loops read into. It is a
+// multiple of the block size so that full reads are hashed without being
+// copied into the digest's partial block.
+const BufSize = 512 * blockSize
+
+// FileBufSize is the largest buffer ReadFile reads into. Fewer, larger
+// reads save system calls; beyond 128 KiB the gain is negligible on
+// common platforms while the buffer stops fitting in the L2 cache.
+const FileBufSize = 4 * BufSize
+
+// Stats describes where the time went while hashing a reader. It has the
+// fields, in the same order, of the HashStats types of crypto/md5 and
+// crypto/sha256, which convert from it.
+type Stats struct {
+	BytesRead int64
+	Reads     int
+	ReadTime  time.Duration
+	HashTime  time.Duration
+	MaxRead   int
+}
+
+// ReadWithStats writes the data read from r up to io.EOF to h and
+// records in stats how long the reads and the writes to h took. It
+// returns the first error from r other than io.EOF.
+func ReadWithStats(r io.Reader, h io.Writer, stats *Stats) error {
+	buf := make([]byte, BufSize)
+	for {
+		start := time.Now()
+		n, err := r.Read(buf)
+		read := time.Now()
+		stats.Reads++
+		stats.ReadTime += read.Sub(start)
+		if n > 0 {
+			h.Write(buf[:n])
+			stats.HashTime += time.Since(read)
+			stats.BytesRead += int64(n)
+			if n > stats.MaxRead {
+				stats.MaxRead = n
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Split reads r up to io.EOF and writes the records in it, separated by
+// delim, to h, without the delimiters. It calls end after each record,
+// including a final record that has no trailing delimiter; an empty
+// input has no records. end must leave h ready for the next record. Split
+// returns the first error from r other than io.EOF, without calling end
+// for the record it interrupted.
+func Split(r io.Reader, delim byte, h io.Writer, end func()) error {
+	pending := false
+	buf := make([]byte, BufSize)
+	for {
+		n, err := r.Read(buf)
+		p := buf[:n]
+		for len(p) > 0 {
+			i := bytes.IndexByte(p, delim)
+			if i < 0 {
+				h.Write(p)
+				pending = true
+				break
+			}
+			h.Write(p[:i])
+			end()
+			pending = false
+			p = p[i+1:]
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if pending {
+		end()
+	}
+	return nil
+}
+
+// ReadFile writes the contents of the named file to h and returns their
+// size. The file is read in chunks of up to FileBufSize bytes; smaller
+// regular files get a buffer just large enough to hold them. The file is
+// always closed before ReadFile returns. The first error from opening,
+// reading or closing the file is returned.
+func ReadFile(path string, h io.Writer) (size int64, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	n := int64(FileBufSize)
+	if fi, err := f.Stat(); err == nil && fi.Mode().IsRegular() && fi.Size() < n {
+		// Leave room to see EOF, or growth of the file, in the first read.
+		n = (fi.Size() + blockSize) &^ (blockSize - 1)
+	}
+	buf := make([]byte, n)
+	for {
+		nr, err := f.Read(buf)
+		h.Write(buf[:nr])
+		size += int64(nr)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			f.Close()
+			return size, err
+		}
+	}
+	return size, f.Close()
+}
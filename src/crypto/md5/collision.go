@@ -0,0 +1,153 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package md5
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+// Collision detection, after the counter-cryptanalysis approach of
+// sha1dc: every block is checked for being the second block of an
+// identical-prefix collision made with the differential of Wang et al.,
+// which Wang's attack and the widely used fastcoll tool both follow.
+//
+// Such a collision is a pair of two-block messages. The first blocks
+// differ by dm (the words m4, m11 and m14 of the second message exceed
+// those of the first by 2^31, 2^15 and 2^31) and leave the chaining
+// values differing by dIHV. The second blocks differ by dm with the sign
+// of the m11 difference flipped, and cancel dIHV. Given either second
+// block and its input chaining value, the partner block and chaining
+// value follow, and one more compression shows whether they collide.
+
+// dIHV is the chaining value difference after the first block.
+var dIHV = [4]uint32{1 << 31, 1<<31 + 1<<25, 1<<31 + 1<<25, 1<<31 + 1<<25}
+
+// isCollisionBlock reports whether the block b, compressed from the
+// chaining value ihv to out, is the second block of a Wang-type
+// collision, as the message of either sign.
+func isCollisionBlock(ihv, out *[4]uint32, b []byte) bool {
+	for _, sign := range []uint32{1, ^uint32(0)} {
+		var d digest
+		for i := range d.s {
+			d.s[i] = ihv[i] + sign*dIHV[i]
+		}
+		var p [BlockSize]byte
+		copy(p[:], b)
+		m4 := binary.LittleEndian.Uint32(p[4*4:])
+		m11 := binary.LittleEndian.Uint32(p[4*11:])
+		m14 := binary.LittleEndian.Uint32(p[4*14:])
+		binary.LittleEndian.PutUint32(p[4*4:], m4+1<<31)
+		binary.LittleEndian.PutUint32(p[4*11:], m11-sign*(1<<15))
+		binary.LittleEndian.PutUint32(p[4*14:], m14+1<<31)
+		block(&d, p[:])
+		if d.s == *out {
+			return true
+		}
+	}
+	return false
+}
+
+// NewCollisionDetecting returns a new hash.Hash computing the MD5
+// checksum that also checks every block written for the known
+// identical-prefix MD5 collision attack, and a pointer to a flag that is
+// set once such a block has been written. The checksum is unchanged;
+// the flag tells the caller that the input was crafted to collide with
+// another input, so that its MD5 checksum does not identify it. Reset
+// clears the flag.
+//
+// The check finds collisions made with the differential of Wang et al.,
+// which includes those of the fastcoll tool. It does not find
+// chosen-prefix collisions, such as those of HashClash or the Flame
+// malware, which use other differentials. Every block costs three
+// compressions instead of one.
+func NewCollisionDetecting() (h hash.Hash, found *bool) {
+	mustBeEnabled()
+	d := new(collisionDigest)
+	d.Reset()
+	return d, &d.found
+}
+
+// SumCollisionDetecting returns the MD5 checksum of data and reports
+// whether data contains a block of a known collision attack, as checked
+// by the hash returned by NewCollisionDetecting.
+func SumCollisionDetecting(data []byte) (sum [Size]byte, found bool) {
+	var d collisionDigest
+	d.Reset()
+	d.Write(data)
+	return d.checkSum(), d.found
+}
+
+// collisionDigest is a digest that checks each block it compresses with
+// isCollisionBlock. The padding added by Sum is not checked.
+type collisionDigest struct {
+	digest
+	found bool
+}
+
+func (d *collisionDigest) Reset() {
+	if d.frozen {
+		return
+	}
+	d.digest.Reset()
+	d.found = false
+}
+
+// Wipe clears the flag, like Reset.
+func (d *collisionDigest) Wipe() {
+	d.digest.Wipe()
+	d.found = false
+}
+
+func (d *collisionDigest) Write(p []byte) (nn int, err error) {
+	mustBeEnabled()
+	if d.frozen {
+		return d.digest.Write(p)
+	}
+	nn = len(p)
+	d.len += uint64(nn)
+	if d.nx > 0 {
+		n := copy(d.x[d.nx:], p)
+		d.nx += n
+		if d.nx == BlockSize {
+			d.block(d.x[:])
+			d.nx = 0
+		}
+		p = p[n:]
+	}
+	for len(p) >= BlockSize {
+		d.block(p[:BlockSize])
+		p = p[BlockSize:]
+	}
+	if len(p) > 0 {
+		d.nx = copy(d.x[:], p)
+	}
+	return
+}
+
+func (d *collisionDigest) WriteByte(c byte) error {
+	_, err := d.Write([]byte{c})
+	return err
+}
+
+func (d *collisionDigest) WriteVec(bufs [][]byte) (nn int, err error) {
+	for _, b := range bufs {
+		n, err := d.Write(b)
+		nn += n
+		if err != nil {
+			return nn, err
+		}
+	}
+	return nn, nil
+}
+
+// block compresses the single block b and checks it.
+func (d *collisionDigest) block(b []byte) {
+	ihv := d.s
+	block(&d.digest, b)
+	if !d.found && isCollisionBlock(&ihv, &d.s, b) {
+		d.found = true
+	}
+}
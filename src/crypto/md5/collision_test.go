@@ -0,0 +1,86 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !nomd5
+
+package md5
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"testing"
+)
+
+// The two-block MD5 collision published by Wang et al. in 2004. Both
+// messages hash to 79054025255fb1a26e4bc422aef54eb4.
+var (
+	collision1, _ = hex.DecodeString("d131dd02c5e6eec4693d9a0698aff95c2fcab58712467eab4004583eb8fb7f89" +
+		"55ad340609f4b30283e488832571415a085125e8f7cdc99fd91dbdf280373c5b" +
+		"d8823e3156348f5bae6dacd436c919c6dd53e2b487da03fd02396306d248cda0" +
+		"e99f33420f577ee8ce54b67080a80d1ec69821bcb6a8839396f9652b6ff72a70")
+	collision2, _ = hex.DecodeString("d131dd02c5e6eec4693d9a0698aff95c2fcab50712467eab4004583eb8fb7f89" +
+		"55ad340609f4b30283e4888325f1415a085125e8f7cdc99fd91dbd7280373c5b" +
+		"d8823e3156348f5bae6dacd436c919c6dd53e23487da03fd02396306d248cda0" +
+		"e99f33420f577ee8ce54b67080280d1ec69821bcb6a8839396f965ab6ff72a70")
+)
+
+func TestCollisionDetecting(t *testing.T) {
+	if bytes.Equal(collision1, collision2) || Sum(collision1) != Sum(collision2) {
+		t.Fatal("test vectors are not a collision")
+	}
+	suffix := []byte("same suffix on both")
+	for i, m := range [][]byte{collision1, collision2} {
+		for _, in := range [][]byte{m, append(append([]byte(nil), m...), suffix...)} {
+			sum, found := SumCollisionDetecting(in)
+			if sum != Sum(in) {
+				t.Errorf("message %d: SumCollisionDetecting checksum = %x, want %x", i+1, sum, Sum(in))
+			}
+			if !found {
+				t.Errorf("message %d (%d bytes): collision not detected", i+1, len(in))
+			}
+		}
+
+		// Writes split across block boundaries are checked too.
+		h, found := NewCollisionDetecting()
+		for _, b := range m {
+			h.(io.ByteWriter).WriteByte(b)
+		}
+		if !*found {
+			t.Errorf("message %d written byte by byte: collision not detected", i+1)
+		}
+		h.Reset()
+		if *found {
+			t.Errorf("message %d: flag still set after Reset", i+1)
+		}
+	}
+
+	// Changing any byte of the collision defeats it, and the check.
+	for _, n := range []int{0, 4*4 + 1, 63, 64, 64 + 4*11, 127} {
+		m := append([]byte(nil), collision1...)
+		m[n] ^= 1
+		if _, found := SumCollisionDetecting(m); found {
+			t.Errorf("collision detected with byte %d changed", n)
+		}
+	}
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	h, found := NewCollisionDetecting()
+	h.Write(data)
+	if *found {
+		t.Error("collision detected in ordinary data")
+	}
+	if got, want := h.Sum(nil), Sum(data); !bytes.Equal(got, want[:]) {
+		t.Errorf("NewCollisionDetecting checksum = %x, want %x", got, want)
+	}
+}
+
+func BenchmarkSumCollisionDetecting8K(b *testing.B) {
+	b.SetBytes(8192)
+	for i := 0; i < b.N; i++ {
+		SumCollisionDetecting(buf[:8192])
+	}
+}
@@ -2,6 +2,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// +build !nomd5
+
 package md5_test
 
 import (
@@ -40,3 +42,9 @@ func ExampleNew_file() {
 
 	fmt.Printf("%x", h.Sum(nil))
 }
+
+func ExampleSumCode() {
+	code := md5.SumCode([]byte("hello world\n"), 8)
+	fmt.Println(code)
+	// Output: G8C87KI3
+}
@@ -53,6 +53,13 @@ type Data struct {
 	Table2     []uint32
 	Table3     []uint32
 	Table4     []uint32
+	Trace      bool // generate blockTrace rather than blockGeneric
+}
+
+// Traced returns a copy of d that generates blockTrace.
+func (d Data) Traced() Data {
+	d.Trace = true
+	return d
 }
 
 var funcs = template.FuncMap{
@@ -61,6 +68,7 @@ var funcs = template.FuncMap{
 	"rotate":  rotate,
 	"idx":     idx,
 	"seq":     seq,
+	"add":     add,
 }
 
 func dup(count int, x []int) []int {
@@ -95,6 +103,10 @@ func idx(round, index int) int {
 	return v
 }
 
+func add(x, y int) int {
+	return x + y
+}
+
 func seq(i int) []int {
 	s := make([]int, i)
 	for i := range s {
@@ -205,7 +217,17 @@ import (
 	"math/bits"
 )
 
+{{template "block" .}}
+// blockTrace is blockGeneric with a call to trace after each of the 64
+// steps of every block, passing the step number and the four state
+// words. It is used by Trace.
+{{template "block" .Traced}}
+{{- define "block" -}}
+{{if .Trace -}}
+func blockTrace(dig *digest, p []byte, trace func(step int, a, b, c, d uint32)) {
+{{- else -}}
 func blockGeneric(dig *digest, p []byte) {
+{{- end}}
 	// load state
 	a, b, c, d := dig.s[0], dig.s[1], dig.s[2], dig.s[3]
 
@@ -222,38 +244,50 @@ func blockGeneric(dig *digest, p []byte) {
 			{{printf "x%x := binary.LittleEndian.Uint32(q[4*%#x:])" $i $i}}
 		{{end}}
 
-		// round 1
+		{{template "rounds" .}}
+		// add saved state
+		a += aa
+		b += bb
+		c += cc
+		d += dd
+	}
+
+	// save state
+	dig.s[0], dig.s[1], dig.s[2], dig.s[3] = a, b, c, d
+}
+{{end}}
+{{- define "rounds" -}}
+// round 1
 		{{range $i, $s := dup 4 .Shift1 -}}
 			{{printf "arg0 = arg1 + bits.RotateLeft32((((arg2^arg3)&arg1)^arg3)+arg0+x%x+%#08x, %d)" (idx 1 $i) (index $.Table1 $i) $s | relabel}}
+			{{if $.Trace}}{{printf "trace(%d, a, b, c, d)" (add 0 $i)}}
+			{{end -}}
 			{{rotate -}}
 		{{end}}
 	
 		// round 2
 		{{range $i, $s := dup 4 .Shift2 -}}
 			{{printf "arg0 = arg1 + bits.RotateLeft32((((arg1^arg2)&arg3)^arg2)+arg0+x%x+%#08x, %d)" (idx 2 $i) (index $.Table2 $i) $s | relabel}}
+			{{if $.Trace}}{{printf "trace(%d, a, b, c, d)" (add 16 $i)}}
+			{{end -}}
 			{{rotate -}}
 		{{end}}
 	
 		// round 3
 		{{range $i, $s := dup 4 .Shift3 -}}
 			{{printf "arg0 = arg1 + bits.RotateLeft32((arg1^arg2^arg3)+arg0+x%x+%#08x, %d)" (idx 3 $i) (index $.Table3 $i) $s | relabel}}
+			{{if $.Trace}}{{printf "trace(%d, a, b, c, d)" (add 32 $i)}}
+			{{end -}}
 			{{rotate -}}
 		{{end}}
 	
 		// round 4
 		{{range $i, $s := dup 4 .Shift4 -}}
 			{{printf "arg0 = arg1 + bits.RotateLeft32((arg2^(arg1|^arg3))+arg0+x%x+%#08x, %d)" (idx 4 $i) (index $.Table4 $i) $s | relabel}}
+			{{if $.Trace}}{{printf "trace(%d, a, b, c, d)" (add 48 $i)}}
+			{{end -}}
 			{{rotate -}}
 		{{end}}
 
-		// add saved state
-		a += aa
-		b += bb
-		c += cc
-		d += dd
-	}
-
-	// save state
-	dig.s[0], dig.s[1], dig.s[2], dig.s[3] = a, b, c, d
-}
+{{end}}
 `
@@ -12,13 +12,17 @@ package md5
 
 import (
 	"crypto"
+	"crypto/internal/hashenc"
+	"crypto/subtle"
 	"encoding/binary"
 	"errors"
 	"hash"
 )
 
 func init() {
-	crypto.RegisterHash(crypto.MD5, New)
+	if !disabled {
+		crypto.RegisterHash(crypto.MD5, New)
+	}
 }
 
 // The size of an MD5 checksum in bytes.
@@ -40,9 +44,14 @@ type digest struct {
 	x   [BlockSize]byte
 	nx  int
 	len uint64
+
+	frozen bool // Write, Reset and UnmarshalBinary are disabled; see Freeze
 }
 
 func (d *digest) Reset() {
+	if d.frozen {
+		return
+	}
 	d.s[0] = init0
 	d.s[1] = init1
 	d.s[2] = init2
@@ -51,13 +60,14 @@ func (d *digest) Reset() {
 	d.len = 0
 }
 
-const (
-	magic         = "md5\x01"
-	marshaledSize = len(magic) + 4*4 + BlockSize + 8
-)
+const magic = "md5\x01"
+
+// MarshaledSize is the length of the hash state returned by the
+// MarshalBinary method of the hash returned by New.
+const MarshaledSize = len(magic) + 4*4 + BlockSize + 8
 
 func (d *digest) MarshalBinary() ([]byte, error) {
-	b := make([]byte, 0, marshaledSize)
+	b := make([]byte, 0, MarshaledSize)
 	b = append(b, magic...)
 	b = appendUint32(b, d.s[0])
 	b = appendUint32(b, d.s[1])
@@ -70,10 +80,13 @@ func (d *digest) MarshalBinary() ([]byte, error) {
 }
 
 func (d *digest) UnmarshalBinary(b []byte) error {
+	if d.frozen {
+		return errors.New("crypto/md5: unmarshal into frozen hash")
+	}
 	if len(b) < len(magic) || string(b[:len(magic)]) != magic {
 		return errors.New("crypto/md5: invalid hash state identifier")
 	}
-	if len(b) != marshaledSize {
+	if len(b) != MarshaledSize {
 		return errors.New("crypto/md5: invalid hash state size")
 	}
 	b = b[len(magic):]
@@ -87,6 +100,42 @@ func (d *digest) UnmarshalBinary(b []byte) error {
 	return nil
 }
 
+// MarshalText encodes the same state as MarshalBinary in hexadecimal, so
+// that it can be stored by text-based encoders such as encoding/json.
+func (d *digest) MarshalText() ([]byte, error) {
+	b, err := d.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	text := make([]byte, 2*len(b))
+	hashenc.EncodeHex(text, b)
+	return text, nil
+}
+
+// UnmarshalText restores the state encoded by MarshalText.
+func (d *digest) UnmarshalText(text []byte) error {
+	if len(text)%2 != 0 {
+		return errors.New("crypto/md5: invalid hash state size")
+	}
+	b, ok := hashenc.DecodeHex(text)
+	if !ok {
+		return errors.New("crypto/md5: invalid hash state encoding")
+	}
+	return d.UnmarshalBinary(b)
+}
+
+// GobEncode implements gob.GobEncoder with the same encoding as
+// MarshalBinary.
+func (d *digest) GobEncode() ([]byte, error) {
+	return d.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder; it accepts the encoding produced by
+// GobEncode or MarshalBinary.
+func (d *digest) GobDecode(b []byte) error {
+	return d.UnmarshalBinary(b)
+}
+
 func appendUint64(b []byte, x uint64) []byte {
 	var a [8]byte
 	binary.BigEndian.PutUint64(a[:], x)
@@ -108,14 +157,202 @@ func consumeUint32(b []byte) ([]byte, uint32) {
 }
 
 // New returns a new hash.Hash computing the MD5 checksum. The Hash also
-// implements encoding.BinaryMarshaler and encoding.BinaryUnmarshaler to
-// marshal and unmarshal the internal state of the hash.
+// implements encoding.BinaryMarshaler, encoding.BinaryUnmarshaler,
+// encoding.TextMarshaler and encoding.TextUnmarshaler to marshal and
+// unmarshal the internal state of the hash.
 func New() hash.Hash {
+	mustBeEnabled()
 	d := new(digest)
 	d.Reset()
 	return d
 }
 
+// NewResumable returns a new hash.Hash computing the MD5 checksum that
+// resumes from prefixState, a hash state returned by MarshalBinary. It is
+// equivalent to calling UnmarshalBinary on the hash returned by New, and
+// returns the same errors if prefixState has the wrong identifier or size.
+func NewResumable(prefixState []byte) (hash.Hash, error) {
+	mustBeEnabled()
+	d := new(digest)
+	if err := d.UnmarshalBinary(prefixState); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// A Digest computes an MD5 checksum like the hash returned by New, but is
+// a concrete type that can be embedded in other structs or kept on the
+// stack, so that it need not be allocated on the heap. The zero value is
+// ready to use. A *Digest implements hash.Hash.
+type Digest struct {
+	d     digest
+	ready bool // d has been Reset
+}
+
+// Reset resets the Digest to its initial state.
+func (d *Digest) Reset() {
+	d.d.Reset()
+	d.ready = true
+}
+
+// Write adds p to the data being hashed. It never returns an error.
+func (d *Digest) Write(p []byte) (nn int, err error) {
+	if !d.ready {
+		d.Reset()
+	}
+	return d.d.Write(p)
+}
+
+// WriteVec adds the concatenation of bufs to the data being hashed. It
+// never returns an error.
+func (d *Digest) WriteVec(bufs [][]byte) (nn int, err error) {
+	if !d.ready {
+		d.Reset()
+	}
+	return d.d.WriteVec(bufs)
+}
+
+// Sum appends the checksum of the data written so far to b and returns
+// the resulting slice. It does not change the underlying hash state.
+func (d *Digest) Sum(b []byte) []byte {
+	if !d.ready {
+		d.Reset()
+	}
+	return d.d.Sum(b)
+}
+
+// Len returns the number of bytes written since the Digest was last reset.
+func (d *Digest) Len() uint64 { return d.d.len }
+
+// Size returns Size.
+func (d *Digest) Size() int { return Size }
+
+// BlockSize returns BlockSize.
+func (d *Digest) BlockSize() int { return BlockSize }
+
+// NewStrict returns a new hash.Hash computing the MD5 checksum that
+// guards against reusing the hash by mistake: once Sum has been called,
+// Write panics until Reset is called. Apart from that it behaves like the
+// hash returned by New.
+func NewStrict() hash.Hash {
+	mustBeEnabled()
+	d := new(strictDigest)
+	d.Reset()
+	return d
+}
+
+// strictDigest is a digest that refuses writes between Sum and Reset.
+type strictDigest struct {
+	digest
+	summed bool // Sum has been called since the last Reset
+}
+
+func (d *strictDigest) Write(p []byte) (nn int, err error) {
+	if d.summed {
+		panic("crypto/md5: Write after Sum without Reset")
+	}
+	return d.digest.Write(p)
+}
+
+func (d *strictDigest) WriteVec(bufs [][]byte) (nn int, err error) {
+	if d.summed {
+		panic("crypto/md5: Write after Sum without Reset")
+	}
+	return d.digest.WriteVec(bufs)
+}
+
+func (d *strictDigest) WriteByte(c byte) error {
+	if d.summed {
+		panic("crypto/md5: Write after Sum without Reset")
+	}
+	return d.digest.WriteByte(c)
+}
+
+func (d *strictDigest) Sum(in []byte) []byte {
+	d.summed = true
+	return d.digest.Sum(in)
+}
+
+func (d *strictDigest) Reset() {
+	d.summed = false
+	d.digest.Reset()
+}
+
+// Len returns the number of bytes written to the hash since it was last
+// reset. For a hash restored with
+// UnmarshalBinary or created by NewFromState, the count includes the
+// bytes covered by the restored state.
+func (d *digest) Len() uint64 { return d.len }
+
+// State returns a copy of the chaining value and the number of bytes
+// written so far. It does not pad or finalize the hash, and writing may
+// continue afterwards. The chaining value only covers the full blocks
+// written; bytes of a partial block are still buffered. State is
+// therefore only meaningful, for example as an interior node of a hash
+// tree, after a multiple of BlockSize bytes has been written.
+func (d *digest) State() ([4]uint32, uint64) {
+	return d.s, d.len
+}
+
+// NewFromState returns a new hash.Hash computing the MD5 checksum that
+// resumes from the chaining value s after length bytes, as returned by
+// the State method. Writes continue the message from byte length on.
+//
+// Since s may also be taken from a checksum, as the four little-endian
+// words of the output of Sum, with length the padded length of that
+// message, NewFromState can continue a finished hash: this is the length
+// extension attack that makes MD5(key || message) unsafe as a MAC.
+//
+// NewFromState panics if length is not a multiple of BlockSize.
+func NewFromState(s [4]uint32, length uint64) hash.Hash {
+	mustBeEnabled()
+	if length%BlockSize != 0 {
+		panic("crypto/md5: length is not a multiple of the block size")
+	}
+	d := new(digest)
+	d.s = s
+	d.len = length
+	return d
+}
+
+// Words returns the checksum of the data written so far as four words.
+// MD5 outputs its words in little-endian order, unlike SHA256, so word i
+// is binary.LittleEndian.Uint32(sum[4*i:]) where sum is the output of
+// Sum. A copy of d is finalized; d itself is not changed.
+func (d *digest) Words() [4]uint32 {
+	d0 := *d
+	d0.frozen = false // checkSum writes the padding
+	d0.checkSum()
+	return d0.s
+}
+
+// Freeze makes d immutable, for example to guarantee that a digest whose
+// state has been recorded with MarshalBinary is not changed afterwards.
+// Once d is frozen, Write and UnmarshalBinary return an error without
+// changing the state and Reset does nothing. Sum and the marshaling
+// methods keep working. A frozen digest cannot be unfrozen.
+func (d *digest) Freeze() {
+	d.frozen = true
+}
+
+// Wipe overwrites the internal state of d, that is the chaining value,
+// the buffered input and the length, with zeros, then resets d so that it
+// can be used again. Call it after hashing secret data to shorten the
+// time the data lingers in memory. Wiping is best effort: it does not
+// guarantee that copies made by the runtime or by the caller, such as the
+// copy that Sum works on, are cleared.
+func (d *digest) Wipe() {
+	for i := range d.s {
+		d.s[i] = 0
+	}
+	for i := range d.x {
+		d.x[i] = 0
+	}
+	d.nx = 0
+	d.len = 0
+	d.Reset()
+}
+
 func (d *digest) Size() int { return Size }
 
 func (d *digest) BlockSize() int { return BlockSize }
@@ -124,6 +361,10 @@ func (d *digest) Write(p []byte) (nn int, err error) {
 	// Note that we currently call block or blockGeneric
 	// directly (guarded using haveAsm) because this allows
 	// escape analysis to see that p and d don't escape.
+	mustBeEnabled()
+	if d.frozen {
+		return 0, errors.New("crypto/md5: write to frozen hash")
+	}
 
 	//获取写入字节数，更新d.len的值
 	nn = len(p)
@@ -188,13 +429,82 @@ func (d *digest) Write(p []byte) (nn int, err error) {
 	return
 }
 
+// WriteVec adds the concatenation of bufs to the running hash, as if each
+// buffer were passed to Write in turn, and returns the total number of
+// bytes written. It suits messages assembled from separate header and
+// payload fragments, which need not be joined first.
+func (d *digest) WriteVec(bufs [][]byte) (nn int, err error) {
+	for _, b := range bufs {
+		n, err := d.Write(b)
+		nn += n
+		if err != nil {
+			return nn, err
+		}
+	}
+	return nn, nil
+}
+
+// WriteByte adds c to the running hash. It implements io.ByteWriter and
+// behaves like Write([]byte{c}), without the cost of a slice per byte.
+func (d *digest) WriteByte(c byte) error {
+	mustBeEnabled()
+	if d.frozen {
+		return errors.New("crypto/md5: write to frozen hash")
+	}
+	d.len++
+	d.x[d.nx] = c
+	d.nx++
+	if d.nx == BlockSize {
+		if haveAsm {
+			block(d, d.x[:])
+		} else {
+			blockGeneric(d, d.x[:])
+		}
+		d.nx = 0
+	}
+	return nil
+}
+
 func (d *digest) Sum(in []byte) []byte {
 	// Make a copy of d so that caller can keep writing and summing.
 	d0 := *d
+	d0.frozen = false // checkSum writes the padding
 	hash := d0.checkSum()
 	return append(in, hash[:]...)
 }
 
+// SumWithLen is like Sum but also returns the number of bytes hashed,
+// not counting the padding added by finalization.
+func (d *digest) SumWithLen(in []byte) ([]byte, uint64) {
+	return d.Sum(in), d.len
+}
+
+// SumHex returns the checksum of the data written so far in lowercase
+// hexadecimal, without changing the underlying hash state.
+func (d *digest) SumHex() string {
+	d0 := *d
+	d0.frozen = false // checkSum writes the padding
+	sum := d0.checkSum()
+	var text [2 * Size]byte
+	hashenc.EncodeHex(text[:], sum[:])
+	return string(text[:])
+}
+
+// SumReuse finalizes d in place and returns dst with the checksum copied
+// into it. Unlike Sum it does not copy the hash state first, which saves
+// time when the hash is not used again: the padding is written into d, so
+// d must be Reset before it can be written to or summed again.
+func (d *digest) SumReuse(dst [Size]byte) [Size]byte {
+	if d.frozen {
+		// checkSum cannot write the padding into a frozen digest.
+		d0 := *d
+		d0.frozen = false
+		d = &d0
+	}
+	dst = d.checkSum()
+	return dst
+}
+
 func (d *digest) checkSum() [Size]byte {
 	// Append 0x80 to the end of the message and then append zeros
 	// until the length is a multiple of 56 bytes. Finally append
@@ -233,6 +543,21 @@ func (d *digest) checkSum() [Size]byte {
 	return digest
 }
 
+// mustBeEnabled panics if the package was built with the nomd5 build
+// tag. Every function that hashes, and every constructor, calls it first.
+func mustBeEnabled() {
+	if disabled {
+		panic("crypto/md5: MD5 is disabled in this build by the nomd5 build tag")
+	}
+}
+
+// padBlocks builds the final one or two blocks of a message of length
+// bytes in buf and returns them, as hashenc.Pad does with the little-endian
+// length field of MD5.
+func padBlocks(buf *[2 * BlockSize]byte, rest []byte, length uint64) []byte {
+	return hashenc.Pad(buf, rest, length, binary.LittleEndian)
+}
+
 // Sum returns the MD5 checksum of the data.
 func Sum(data []byte) [Size]byte {
 	var d digest
@@ -240,3 +565,157 @@ func Sum(data []byte) [Size]byte {
 	d.Write(data)
 	return d.checkSum()
 }
+
+// AppendSum appends the MD5 checksum of data to dst and returns the
+// resulting slice. It does not allocate if dst has room for Size more
+// bytes, which lets callers hashing many inputs reuse one buffer.
+func AppendSum(dst, data []byte) []byte {
+	var d digest
+	d.Reset()
+	d.Write(data)
+	sum := d.checkSum()
+	return append(dst, sum[:]...)
+}
+
+// SumHex returns the MD5 checksum of data in lowercase hexadecimal.
+func SumHex(data []byte) string {
+	sum := Sum(data)
+	var text [2 * Size]byte
+	hashenc.EncodeHex(text[:], sum[:])
+	return string(text[:])
+}
+
+// SumHexString returns the MD5 checksum of s in lowercase hexadecimal.
+func SumHexString(s string) string {
+	return SumHex([]byte(s))
+}
+
+// SumSalted returns MD5(salt || data), as computed by some legacy password
+// and authentication systems, without concatenating salt and data.
+//
+// MD5 is broken, and a single fast hash is not a safe way to store
+// passwords even with a salt. SumSalted exists only to interoperate with
+// existing systems; new code should use a password hashing function such
+// as bcrypt, scrypt or Argon2.
+func SumSalted(salt, data []byte) [Size]byte {
+	var d digest
+	d.Reset()
+	d.Write(salt)
+	d.Write(data)
+	return d.checkSum()
+}
+
+// SumSlices returns the MD5 checksum of the concatenation of slices,
+// without joining them first.
+func SumSlices(slices ...[]byte) [Size]byte {
+	var d digest
+	d.Reset()
+	for _, s := range slices {
+		d.Write(s)
+	}
+	return d.checkSum()
+}
+
+// DeriveNonce returns a deterministic nonce of size bytes derived from key
+// and counter. The nonce is the first size bytes of MD5(key || counter),
+// where counter is encoded as 8 big-endian bytes.
+//
+// The same key and counter always produce the same nonce, so DeriveNonce
+// must not be used where a random nonce is required. It is an error to
+// request more than Size bytes or a negative size.
+func DeriveNonce(key []byte, counter uint64, size int) ([]byte, error) {
+	if size < 0 || size > Size {
+		return nil, errors.New("crypto/md5: invalid nonce size")
+	}
+	var d digest
+	d.Reset()
+	d.Write(key)
+	var c [8]byte
+	binary.BigEndian.PutUint64(c[:], counter)
+	d.Write(c[:])
+	sum := d.checkSum()
+	nonce := make([]byte, size)
+	copy(nonce, sum[:])
+	return nonce, nil
+}
+
+// HasAsm reports whether the MD5 block function runs an assembly
+// implementation on the current architecture.
+func HasAsm() bool {
+	return haveAsm
+}
+
+// Compress applies the MD5 compression function to one block, updating
+// the chaining value in state in place. It uses the same implementation
+// as the hashes returned by New, including any assembly.
+//
+// Compress is a low-level primitive for building other constructions. It
+// does not pad or count the message, so on its own it does not compute an
+// MD5 checksum.
+func Compress(state *[4]uint32, b *[BlockSize]byte) {
+	mustBeEnabled()
+	d := digest{s: *state}
+	if haveAsm {
+		block(&d, b[:])
+	} else {
+		blockGeneric(&d, b[:])
+	}
+	*state = d.s
+}
+
+// Equal reports whether sum1 and sum2 are equal MD5 checksums.
+// The comparison runs in constant time.
+func Equal(sum1, sum2 [Size]byte) bool {
+	return subtle.ConstantTimeCompare(sum1[:], sum2[:]) == 1
+}
+
+// Commit returns a hash commitment to value, blinded by nonce:
+//
+//	MD5(len(nonce) || nonce || value)
+//
+// where len(nonce) is encoded as 8 big-endian bytes. Prefixing the length
+// of the nonce makes the split between nonce and value unambiguous, so a
+// commitment cannot be opened to a different (nonce, value) pair with the
+// same concatenation. The nonce should be at least 16 random bytes and
+// must never be reused; it is revealed together with value to open the
+// commitment.
+//
+// MD5 is not collision resistant, so a party that chooses its own nonce
+// can create commitments that open to two different values. Commit should
+// be used only for compatibility with existing protocols; new code should
+// use crypto/sha256's Commit instead.
+func Commit(value, nonce []byte) [Size]byte {
+	var d digest
+	d.Reset()
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(len(nonce)))
+	d.Write(n[:])
+	d.Write(nonce)
+	d.Write(value)
+	return d.checkSum()
+}
+
+// VerifyCommitment reports whether value and nonce open commitment, that
+// is, whether commitment == Commit(value, nonce). The comparison runs in
+// constant time.
+func VerifyCommitment(commitment [Size]byte, value, nonce []byte) bool {
+	return Equal(commitment, Commit(value, nonce))
+}
+
+// MaxCodeLen is the longest code SumCode will produce. Each base-45
+// digit carries about 5.49 bits, and 23 digits is the most that a 128-bit
+// checksum can fill with uniformly distributed values.
+const MaxCodeLen = 23
+
+// SumCode returns a short code derived from the MD5 checksum of data
+// that can be stored in a QR code using alphanumeric mode. The checksum is
+// read as a big-endian integer and written in base 45 using the digits
+// "0-9", "A-Z" and " $%*+-./:"; the code is the last length digits.
+// SumCode panics if length is not between 1 and MaxCodeLen.
+func SumCode(data []byte, length int) string {
+	if length < 1 || length > MaxCodeLen {
+		panic("crypto/md5: invalid code length")
+	}
+	sum := Sum(data)
+	return hashenc.EncodeCode(sum[:], length)
+}
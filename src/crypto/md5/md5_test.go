@@ -2,15 +2,23 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// +build !nomd5
+
 package md5
 
 import (
 	"bytes"
 	"crypto/rand"
 	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"hash"
 	"io"
+	"runtime"
+	"strings"
 	"testing"
 	"unsafe"
 )
@@ -211,6 +219,776 @@ func TestLargeHashes(t *testing.T) {
 	}
 }
 
+var deriveNonceTests = []struct {
+	key     string
+	counter uint64
+	size    int
+	out     string
+}{
+	{"", 0, 16, "7dea362b3fac8e00956a4952a3d4f474"},
+	{"key", 1, 12, "d57b8aedb1c65c6abe20834a"},
+	{"key", 1<<64 - 1, 8, "247a4fb1c7e8dc07"},
+	{"key", 7, 0, ""},
+}
+
+func TestDeriveNonce(t *testing.T) {
+	for _, tt := range deriveNonceTests {
+		nonce, err := DeriveNonce([]byte(tt.key), tt.counter, tt.size)
+		if err != nil {
+			t.Errorf("DeriveNonce(%q, %d, %d) error: %v", tt.key, tt.counter, tt.size, err)
+			continue
+		}
+		if s := fmt.Sprintf("%x", nonce); s != tt.out {
+			t.Errorf("DeriveNonce(%q, %d, %d) = %s want %s", tt.key, tt.counter, tt.size, s, tt.out)
+		}
+	}
+	for _, size := range []int{-1, Size + 1} {
+		if _, err := DeriveNonce([]byte("key"), 0, size); err == nil {
+			t.Errorf("DeriveNonce with size %d: no error when one was expected", size)
+		}
+	}
+}
+
+func TestHasAsm(t *testing.T) {
+	switch runtime.GOARCH {
+	case "386", "amd64", "arm", "arm64", "ppc64", "ppc64le", "s390x":
+		if !HasAsm() {
+			t.Errorf("HasAsm() = false on %s, want true", runtime.GOARCH)
+		}
+	default:
+		if HasAsm() {
+			t.Errorf("HasAsm() = true on %s, want false", runtime.GOARCH)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a := Sum([]byte("abc"))
+	b := a
+	if !Equal(a, b) {
+		t.Errorf("Equal(%x, %x) = false, want true", a, b)
+	}
+	for _, i := range []int{0, Size - 1} {
+		c := a
+		c[i] ^= 1
+		if Equal(a, c) {
+			t.Errorf("Equal(%x, %x) = true, want false", a, c)
+		}
+	}
+}
+
+func TestSumCode(t *testing.T) {
+	tests := []struct {
+		in     string
+		length int
+		out    string
+	}{
+		{"", MaxCodeLen, "U3-J2QH8N CAO-2AL6*7CQL"},
+		{"abc", 1, "P"},
+		{"hello world\n", 8, "G8C87KI3"},
+	}
+	for _, tt := range tests {
+		if code := SumCode([]byte(tt.in), tt.length); code != tt.out {
+			t.Errorf("SumCode(%q, %d) = %q, want %q", tt.in, tt.length, code, tt.out)
+		}
+	}
+	for _, length := range []int{0, MaxCodeLen + 1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("SumCode with length %d did not panic", length)
+				}
+			}()
+			SumCode(nil, length)
+		}()
+	}
+}
+
+func TestMarshalText(t *testing.T) {
+	for _, g := range golden {
+		h := New()
+		io.WriteString(h, g.in[:len(g.in)/2])
+
+		type config struct {
+			Name  string
+			State *digest
+		}
+		data, err := json.Marshal(config{"x", h.(*digest)})
+		if err != nil {
+			t.Errorf("could not marshal: %v", err)
+			continue
+		}
+		var c config
+		if err := json.Unmarshal(data, &c); err != nil {
+			t.Errorf("could not unmarshal %s: %v", data, err)
+			continue
+		}
+		h2 := c.State
+
+		io.WriteString(h, g.in[len(g.in)/2:])
+		io.WriteString(h2, g.in[len(g.in)/2:])
+
+		if actual, actual2 := h.Sum(nil), h2.Sum(nil); !bytes.Equal(actual, actual2) {
+			t.Errorf("md5(%q) = 0x%x != text marshaled 0x%x", g.in, actual, actual2)
+		}
+	}
+}
+
+func TestUnmarshalTextErrors(t *testing.T) {
+	state, err := New().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		t.Fatalf("could not marshal: %v", err)
+	}
+	if string(state[:8]) != "6d643501" {
+		t.Errorf("MarshalText() = %s, want hex prefix 6d643501", state)
+	}
+	tests := []struct {
+		text string
+		err  string
+	}{
+		{string(state[:len(state)-1]), "crypto/md5: invalid hash state size"},
+		{string(state[:len(state)-2]), "crypto/md5: invalid hash state size"},
+		{"6d643502" + string(state[8:]), "crypto/md5: invalid hash state identifier"},
+		{"6d64350z" + string(state[8:]), "crypto/md5: invalid hash state encoding"},
+	}
+	for _, tt := range tests {
+		err := New().(encoding.TextUnmarshaler).UnmarshalText([]byte(tt.text))
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("UnmarshalText(%.16q...) = %v, want %q", tt.text, err, tt.err)
+		}
+	}
+}
+
+var commitTests = []struct {
+	value, nonce string
+	out          string
+}{
+	{"", "", "7dea362b3fac8e00956a4952a3d4f474"},
+	{"hello", "nonce", "798015b3a8d15b6dbb6196ac5873b01c"},
+	{"nonce", "", "d2fdb108ef67bef53ba96cfc7f83341a"},
+	{"value", "0123456789abcdef0123456789abcdef", "ba47a39f475a6289d7fa434e738360ba"},
+}
+
+func TestCommit(t *testing.T) {
+	for _, tt := range commitTests {
+		c := Commit([]byte(tt.value), []byte(tt.nonce))
+		if s := fmt.Sprintf("%x", c); s != tt.out {
+			t.Errorf("Commit(%q, %q) = %s want %s", tt.value, tt.nonce, s, tt.out)
+		}
+		if !VerifyCommitment(c, []byte(tt.value), []byte(tt.nonce)) {
+			t.Errorf("VerifyCommitment(Commit(%q, %q)) = false", tt.value, tt.nonce)
+		}
+	}
+
+	// Moving bytes between the nonce and the value must not open the
+	// commitment.
+	c := Commit([]byte("value"), []byte("nonce"))
+	if VerifyCommitment(c, []byte("evalue"), []byte("nonc")) {
+		t.Error("VerifyCommitment accepted a shifted nonce/value split")
+	}
+	if VerifyCommitment(c, []byte("value!"), []byte("nonce")) {
+		t.Error("VerifyCommitment accepted a different value")
+	}
+}
+
+func TestNewStrict(t *testing.T) {
+	h := NewStrict()
+	io.WriteString(h, "abc")
+	sum := h.Sum(nil)
+	if !bytes.Equal(sum, h.Sum(nil)) {
+		t.Error("second Sum differs from the first")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Write after Sum did not panic")
+			}
+		}()
+		io.WriteString(h, "def")
+	}()
+
+	h.Reset()
+	io.WriteString(h, "abc")
+	if got := h.Sum(nil); !bytes.Equal(got, sum) {
+		t.Errorf("Sum after Reset = %x, want %x", got, sum)
+	}
+
+	// New keeps hashing after Sum.
+	h = New()
+	io.WriteString(h, "abc")
+	h.Sum(nil)
+	io.WriteString(h, "def")
+	want := Sum([]byte("abcdef"))
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("New: Sum after Write after Sum = %x, want %x", got, want)
+	}
+}
+
+func TestState(t *testing.T) {
+	data := make([]byte, 2*BlockSize)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	h := New()
+	h.Write(data)
+	state, n := h.(interface {
+		State() ([4]uint32, uint64)
+	}).State()
+
+	var d digest
+	d.Reset()
+	block(&d, data)
+	if state != d.s || n != uint64(len(data)) {
+		t.Errorf("State() = %x, %d, want %x, %d", state, n, d.s, len(data))
+	}
+
+	// State must not finalize the hash.
+	h.Write([]byte("abc"))
+	want := Sum(append(data, "abc"...))
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("Sum after State = %x, want %x", got, want)
+	}
+}
+
+func TestGob(t *testing.T) {
+	for _, newHash := range []func() hash.Hash{New} {
+		h := newHash()
+		io.WriteString(h, "a mid-stream message spanning more than one block, "+
+			"so that both the chaining value and the buffer matter")
+
+		var b bytes.Buffer
+		if err := gob.NewEncoder(&b).Encode(h); err != nil {
+			t.Fatalf("could not gob encode: %v", err)
+		}
+		h2 := newHash()
+		if err := gob.NewDecoder(&b).Decode(h2); err != nil {
+			t.Fatalf("could not gob decode: %v", err)
+		}
+
+		io.WriteString(h, "and the rest")
+		io.WriteString(h2, "and the rest")
+		if actual, actual2 := h.Sum(nil), h2.Sum(nil); !bytes.Equal(actual, actual2) {
+			t.Errorf("sum = 0x%x != gob decoded 0x%x", actual, actual2)
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	data := make([]byte, 200)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	for _, n := range []int{0, 1, 55, 56, 64, 119, 200} {
+		// Pad the message by hand: 0x80, zeros, and the bit length.
+		msg := append([]byte(nil), data[:n]...)
+		msg = append(msg, 0x80)
+		for len(msg)%BlockSize != 56 {
+			msg = append(msg, 0)
+		}
+		var length [8]byte
+		binary.LittleEndian.PutUint64(length[:], uint64(n)<<3)
+		msg = append(msg, length[:]...)
+
+		state := [4]uint32{init0, init1, init2, init3}
+		for p := msg; len(p) > 0; p = p[BlockSize:] {
+			var b [BlockSize]byte
+			copy(b[:], p)
+			Compress(&state, &b)
+		}
+		var sum [Size]byte
+		for i, x := range state {
+			binary.LittleEndian.PutUint32(sum[4*i:], x)
+		}
+		if want := Sum(data[:n]); sum != want {
+			t.Errorf("Compress over %d padded bytes = %x, want %x", n, sum, want)
+		}
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	h := New()
+	io.WriteString(h, "abc")
+	want := h.Sum(nil)
+	state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		t.Fatalf("could not marshal: %v", err)
+	}
+
+	h.(interface{ Freeze() }).Freeze()
+	if n, err := io.WriteString(h, "def"); n != 0 || err == nil {
+		t.Errorf("Write after Freeze = %d, %v, want 0 and an error", n, err)
+	}
+	h.Reset()
+	if err := h.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err == nil {
+		t.Error("UnmarshalBinary after Freeze: no error when one was expected")
+	}
+	if got := h.Sum(nil); !bytes.Equal(got, want) {
+		t.Errorf("Sum after Freeze = %x, want %x", got, want)
+	}
+	if got := h.Sum(nil); !bytes.Equal(got, want) {
+		t.Errorf("second Sum after Freeze = %x, want %x", got, want)
+	}
+	state2, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		t.Fatalf("could not marshal frozen hash: %v", err)
+	}
+	if !bytes.Equal(state2, state) {
+		t.Errorf("MarshalBinary after Freeze = %q, want %q", state2, state)
+	}
+}
+
+func TestSumSlices(t *testing.T) {
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	tests := [][][]byte{
+		nil,
+		{},
+		{data[:0]},
+		{data[:3]},
+		{data[:100]},
+		{data[:10], data[10:70], data[70:71], data[71:71], data[71:200], data[200:]},
+	}
+	for _, slices := range tests {
+		var joined []byte
+		for _, s := range slices {
+			joined = append(joined, s...)
+		}
+		if got, want := SumSlices(slices...), Sum(joined); got != want {
+			t.Errorf("SumSlices(%d slices, %d bytes) = %x, want %x", len(slices), len(joined), got, want)
+		}
+	}
+	if got, want := SumSlices(), Sum(nil); got != want {
+		t.Errorf("SumSlices() = %x, want %x", got, want)
+	}
+}
+
+func TestAppendSum(t *testing.T) {
+	for _, g := range golden {
+		prefix := []byte("prefix")
+		got := AppendSum(prefix, []byte(g.in))
+		if s := string(got[:len(prefix)]); s != "prefix" {
+			t.Errorf("AppendSum(%q) overwrote the prefix: %q", g.in, s)
+		}
+		if s := fmt.Sprintf("%x", got[len(prefix):]); s != g.out {
+			t.Errorf("AppendSum(%q) = %s, want %s", g.in, s, g.out)
+		}
+	}
+	if got := AppendSum(nil, nil); len(got) != Size {
+		t.Errorf("len(AppendSum(nil, nil)) = %d, want %d", len(got), Size)
+	}
+
+	dst := make([]byte, 0, Size)
+	if n := testing.AllocsPerRun(10, func() { AppendSum(dst, buf[:100]) }); n > 0 {
+		t.Errorf("AppendSum allocs = %v, want 0", n)
+	}
+}
+
+func TestDigest(t *testing.T) {
+	var _ hash.Hash = (*Digest)(nil)
+	for _, g := range golden {
+		var d Digest
+		io.WriteString(&d, g.in[:len(g.in)/2])
+		io.WriteString(&d, g.in[len(g.in)/2:])
+		if s := fmt.Sprintf("%x", d.Sum(nil)); s != g.out {
+			t.Errorf("Digest of %q = %s, want %s", g.in, s, g.out)
+		}
+		d.Reset()
+		io.WriteString(&d, g.in)
+		if s := fmt.Sprintf("%x", d.Sum(nil)); s != g.out {
+			t.Errorf("Digest of %q after Reset = %s, want %s", g.in, s, g.out)
+		}
+	}
+
+	var d Digest
+	if s := fmt.Sprintf("%x", d.Sum(nil)); s != golden[0].out {
+		t.Errorf("Sum of a zero Digest = %s, want %s", s, golden[0].out)
+	}
+	if d.Size() != Size || d.BlockSize() != BlockSize {
+		t.Errorf("Digest Size, BlockSize = %d, %d, want %d, %d", d.Size(), d.BlockSize(), Size, BlockSize)
+	}
+
+	sum := make([]byte, 0, Size)
+	if n := testing.AllocsPerRun(10, func() {
+		var d Digest
+		d.Write(buf[:100])
+		d.Sum(sum)
+	}); n > 0 {
+		t.Errorf("Digest allocates %v times, want 0", n)
+	}
+}
+
+func TestTrace(t *testing.T) {
+	data := make([]byte, 200)
+	for i := range data {
+		data[i] = byte(i * 3)
+	}
+	for _, n := range []int{0, 1, 55, 56, 63, 64, 119, 120, 200} {
+		var steps int
+		var last [4]uint32
+		sum := Trace(data[:n], func(step int, a, b, c, d uint32) {
+			if want := steps % 64; step != want {
+				t.Fatalf("Trace of %d bytes: step %d, want %d", n, step, want)
+			}
+			steps++
+			last = [4]uint32{a, b, c, d}
+		})
+		if want := Sum(data[:n]); sum != want {
+			t.Errorf("Trace of %d bytes = %x, want %x", n, sum, want)
+		}
+		if blocks := (n+8)/BlockSize + 1; steps != 64*blocks {
+			t.Errorf("Trace of %d bytes took %d steps, want %d", n, steps, 64*blocks)
+		}
+		if n < BlockSize-8 {
+			// A single block: the checksum is the initial state plus the
+			// state after the last step.
+			init := [4]uint32{init0, init1, init2, init3}
+			var want [Size]byte
+			for i := range last {
+				binary.LittleEndian.PutUint32(want[4*i:], init[i]+last[i])
+			}
+			if sum != want {
+				t.Errorf("Trace of %d bytes: last step state %x does not add up to the checksum %x", n, last, sum)
+			}
+		}
+	}
+}
+
+func TestNewFromState(t *testing.T) {
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i * 5)
+	}
+	for _, n := range []int{0, 64, 128, 256} {
+		h := New()
+		h.Write(data[:n])
+		s, length := h.(*digest).State()
+		h2 := NewFromState(s, length)
+		h2.Write(data[n:])
+		if got, want := h2.Sum(nil), Sum(data); !bytes.Equal(got, want[:]) {
+			t.Errorf("NewFromState after %d bytes = %x, want %x", n, got, want)
+		}
+	}
+
+	// Length extension: continue from the checksum of a message, with the
+	// length of the message and its padding.
+	m := data[:100]
+	sum := Sum(m)
+	var s [4]uint32
+	for i := range s {
+		s[i] = binary.LittleEndian.Uint32(sum[4*i:])
+	}
+	var buf [2 * BlockSize]byte
+	glue := padBlocks(&buf, m[64:], uint64(len(m)))[len(m)-64:]
+	h := NewFromState(s, uint64(len(m)+len(glue)))
+	io.WriteString(h, "suffix")
+	ext := append(append(append([]byte(nil), m...), glue...), "suffix"...)
+	if got, want := h.Sum(nil), Sum(ext); !bytes.Equal(got, want[:]) {
+		t.Errorf("length extension = %x, want %x", got, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("NewFromState did not panic on a partial block length")
+		}
+	}()
+	NewFromState(s, 65)
+}
+
+func TestLen(t *testing.T) {
+	for _, newHash := range []func() hash.Hash{New, func() hash.Hash { return new(Digest) }} {
+		h := newHash().(interface {
+			hash.Hash
+			Len() uint64
+		})
+		if n := h.Len(); n != 0 {
+			t.Errorf("Len of a new hash = %d, want 0", n)
+		}
+		for _, n := range []int{1, 63, 64, 1000} {
+			want := h.Len() + uint64(n)
+			h.Write(make([]byte, n))
+			if got := h.Len(); got != want {
+				t.Errorf("Len after writing %d more bytes = %d, want %d", n, got, want)
+			}
+		}
+		before := h.Len()
+		h.Sum(nil)
+		if got := h.Len(); got != before {
+			t.Errorf("Len after Sum = %d, want %d", got, before)
+		}
+		h.Reset()
+		if n := h.Len(); n != 0 {
+			t.Errorf("Len after Reset = %d, want 0", n)
+		}
+	}
+}
+
+func TestWriteVec(t *testing.T) {
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	bufs := [][]byte{data[:10], nil, data[10:70], data[70:71], data[71:200], data[200:]}
+	for _, h := range []interface {
+		hash.Hash
+		WriteVec([][]byte) (int, error)
+	}{New().(*digest), NewStrict().(*strictDigest), new(Digest)} {
+		h.Write(data[:5])
+		n, err := h.WriteVec(bufs)
+		if n != len(data) || err != nil {
+			t.Errorf("%T: WriteVec = %d, %v, want %d, nil", h, n, err, len(data))
+		}
+		want := SumSlices(data[:5], data)
+		if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Errorf("%T: checksum after WriteVec = %x, want %x", h, got, want)
+		}
+	}
+
+	h := New().(*digest)
+	h.Freeze()
+	if n, err := h.WriteVec(bufs); n != 0 || err == nil {
+		t.Errorf("WriteVec to a frozen hash = %d, %v, want 0 and an error", n, err)
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	if err := SelfTest(); err != nil {
+		t.Fatalf("SelfTest() = %v", err)
+	}
+
+	// A wrong known answer must be reported.
+	saved := selfTests[0].out
+	defer func() { selfTests[0].out = saved }()
+	selfTests[0].out = "\x00" + saved[1:]
+	if err := SelfTest(); err == nil {
+		t.Error("SelfTest() with a corrupted known answer = nil")
+	}
+}
+
+func TestWipe(t *testing.T) {
+	h := New()
+	io.WriteString(h, "secret data that is longer than one block of the hash, "+
+		"so that the chaining value has been updated")
+	d := h.(*digest)
+	d.Wipe()
+	for i, b := range d.x {
+		if b != 0 {
+			t.Fatalf("Wipe left x[%d] = %#x", i, b)
+		}
+	}
+	if d.nx != 0 || d.len != 0 {
+		t.Errorf("Wipe left nx = %d, len = %d", d.nx, d.len)
+	}
+	fresh := New().(*digest)
+	if d.s != fresh.s {
+		t.Errorf("Wipe left chaining value %x, want %x", d.s, fresh.s)
+	}
+
+	io.WriteString(h, "abc")
+	want := Sum([]byte("abc"))
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("Sum after Wipe = %x, want %x", got, want)
+	}
+	h.Reset()
+	io.WriteString(h, "abc")
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("Sum after Wipe and Reset = %x, want %x", got, want)
+	}
+}
+
+func TestSumWithLen(t *testing.T) {
+	h := New()
+	var all []byte
+	for _, s := range []string{"", "a", "bc", strings.Repeat("d", 100), "efg"} {
+		io.WriteString(h, s)
+		all = append(all, s...)
+		sum, n := h.(interface {
+			SumWithLen([]byte) ([]byte, uint64)
+		}).SumWithLen([]byte("prefix"))
+		if n != uint64(len(all)) {
+			t.Errorf("SumWithLen length = %d, want %d", n, len(all))
+		}
+		want := Sum(all)
+		if !bytes.Equal(sum, append([]byte("prefix"), want[:]...)) {
+			t.Errorf("SumWithLen = %x, want prefix and %x", sum, want)
+		}
+	}
+}
+
+func TestSumHex(t *testing.T) {
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	for _, n := range []int{0, 1, 64, 300} {
+		want := hex.EncodeToString(sumSlice(data[:n]))
+		if got := SumHex(data[:n]); got != want {
+			t.Errorf("SumHex(%d bytes) = %s, want %s", n, got, want)
+		}
+		if got := SumHexString(string(data[:n])); got != want {
+			t.Errorf("SumHexString(%d bytes) = %s, want %s", n, got, want)
+		}
+		h := New()
+		h.Write(data[:n])
+		if got := h.(interface{ SumHex() string }).SumHex(); got != want {
+			t.Errorf("SumHex() after %d bytes = %s, want %s", n, got, want)
+		}
+		// SumHex must not disturb the running hash.
+		h.Write([]byte("x"))
+		if got, want := h.Sum(nil), sumSlice(append(data[:n:n], 'x')); !bytes.Equal(got, want) {
+			t.Errorf("Sum after SumHex = %x, want %x", got, want)
+		}
+	}
+}
+
+// sumSlice is Sum returning a slice.
+func sumSlice(data []byte) []byte {
+	s := Sum(data)
+	return s[:]
+}
+
+var saltedTests = []struct {
+	salt, data string
+	out        string
+}{
+	{"", "password", "5f4dcc3b5aa765d61d8327deb882cf99"},
+	{"salt", "password", "67a1e09bb1f83f5007dc119c14d663aa"},
+	{"$1$abcdefgh", "hunter2", "9de3698235f6ca7c649d8554069eee30"},
+	{strings.Repeat("x", 70), "", "7d56c0f83fd1e320d60ea2f13dfdeedd"},
+}
+
+func TestSumSalted(t *testing.T) {
+	for _, tt := range saltedTests {
+		if s := fmt.Sprintf("%x", SumSalted([]byte(tt.salt), []byte(tt.data))); s != tt.out {
+			t.Errorf("SumSalted(%q, %q) = %s want %s", tt.salt, tt.data, s, tt.out)
+		}
+	}
+	for _, g := range golden {
+		if got, want := SumSalted(nil, []byte(g.in)), Sum([]byte(g.in)); got != want {
+			t.Errorf("SumSalted(nil, %q) = %x, want %x", g.in, got, want)
+		}
+	}
+}
+
+func TestSumReuse(t *testing.T) {
+	type reuser interface {
+		hash.Hash
+		SumReuse([Size]byte) [Size]byte
+	}
+	data := make([]byte, 200)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	h := New().(reuser)
+	for _, n := range []int{0, 1, 55, 56, 64, 200} {
+		h.Reset()
+		h.Write(data[:n])
+		if got, want := h.SumReuse([Size]byte{}), Sum(data[:n]); got != want {
+			t.Errorf("SumReuse after %d bytes = %x, want %x", n, got, want)
+		}
+	}
+}
+
+func TestWriteByte(t *testing.T) {
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i*31 + 7)
+	}
+	for _, newHash := range []func() hash.Hash{New, NewStrict} {
+		for _, n := range []int{0, 1, 55, 56, 63, 64, 65, 128, 300} {
+			want := newHash()
+			want.Write(data[:n])
+			h := newHash()
+			bw, ok := h.(io.ByteWriter)
+			if !ok {
+				t.Fatalf("%T does not implement io.ByteWriter", h)
+			}
+			for _, c := range data[:n] {
+				if err := bw.WriteByte(c); err != nil {
+					t.Fatalf("WriteByte: %v", err)
+				}
+			}
+			if got, want := h.Sum(nil), want.Sum(nil); !bytes.Equal(got, want) {
+				t.Errorf("%T: %d calls to WriteByte = %x, want %x", h, n, got, want)
+			}
+		}
+	}
+}
+
+func TestMarshaledSize(t *testing.T) {
+	h := New()
+	h.Write(make([]byte, 100))
+	state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		t.Fatalf("could not marshal: %v", err)
+	}
+	if len(state) != MarshaledSize {
+		t.Errorf("len(MarshalBinary()) = %d, want MarshaledSize = %d", len(state), MarshaledSize)
+	}
+	if err := New().(encoding.BinaryUnmarshaler).UnmarshalBinary(state[:MarshaledSize-1]); err == nil {
+		t.Error("UnmarshalBinary of a truncated state: no error when one was expected")
+	}
+}
+
+func TestNewResumable(t *testing.T) {
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	want := Sum(data)
+	for _, split := range []int{0, 1, 63, 64, 65, 150, 300} {
+		h := New()
+		h.Write(data[:split])
+		state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			t.Fatalf("could not marshal: %v", err)
+		}
+		r, err := NewResumable(state)
+		if err != nil {
+			t.Fatalf("NewResumable(state after %d bytes): %v", split, err)
+		}
+		r.Write(data[split:])
+		if got := r.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Errorf("NewResumable(state after %d bytes) = %x, want %x", split, got, want)
+		}
+	}
+
+	state, _ := New().(encoding.BinaryMarshaler).MarshalBinary()
+	wrongMagic := append([]byte(nil), state...)
+	wrongMagic[0] = 's'
+	for _, b := range [][]byte{nil, state[:len(magic)], state[:MarshaledSize-1], append(state, 0), wrongMagic} {
+		if h, err := NewResumable(b); err == nil || h != nil {
+			t.Errorf("NewResumable(%d byte state) = %v, %v, want nil and an error", len(b), h, err)
+		}
+	}
+}
+
+func TestWords(t *testing.T) {
+	type worder interface {
+		hash.Hash
+		Words() [4]uint32
+	}
+	data := make([]byte, 100)
+	for _, n := range []int{0, 3, 64, 100} {
+		h := New().(worder)
+		h.Write(data[:n])
+		words := h.Words()
+		sum := h.Sum(nil)
+		for i := range words {
+			if want := binary.LittleEndian.Uint32(sum[4*i:]); words[i] != want {
+				t.Errorf("Words() after %d bytes[%d] = %#08x, want %#08x", n, i, words[i], want)
+			}
+		}
+		// Words must not finalize the hash itself.
+		h.Write(data[:n])
+		want := Sum(append(data[:n:n], data[:n]...))
+		if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Errorf("Sum after Words = %x, want %x", got, want)
+		}
+	}
+}
+
 var bench = New()
 var buf = make([]byte, 8192+1)
 var sum = make([]byte, bench.Size())
@@ -254,3 +1032,53 @@ func BenchmarkHash1KUnaligned(b *testing.B) {
 func BenchmarkHash8KUnaligned(b *testing.B) {
 	benchmarkSize(b, 8192, true)
 }
+
+func BenchmarkSumSmall(b *testing.B) {
+	h := New()
+	sum := make([]byte, 0, Size)
+	for i := 0; i < b.N; i++ {
+		h.Reset()
+		h.Write(buf[:8])
+		h.Sum(sum[:0])
+	}
+}
+
+func BenchmarkAppendSumSmall(b *testing.B) {
+	sum := make([]byte, 0, Size)
+	for i := 0; i < b.N; i++ {
+		sum = AppendSum(sum[:0], buf[:8])
+	}
+}
+
+func BenchmarkSumReuseSmall(b *testing.B) {
+	h := New().(interface {
+		hash.Hash
+		SumReuse([Size]byte) [Size]byte
+	})
+	var sum [Size]byte
+	for i := 0; i < b.N; i++ {
+		h.Reset()
+		h.Write(buf[:8])
+		sum = h.SumReuse(sum)
+	}
+}
+
+func BenchmarkWriteByte(b *testing.B) {
+	b.SetBytes(1024)
+	h := New().(io.ByteWriter)
+	for i := 0; i < b.N; i++ {
+		for _, c := range buf[:1024] {
+			h.WriteByte(c)
+		}
+	}
+}
+
+func BenchmarkWriteOneByte(b *testing.B) {
+	b.SetBytes(1024)
+	h := New()
+	for i := 0; i < b.N; i++ {
+		for j := range buf[:1024] {
+			h.Write(buf[j : j+1])
+		}
+	}
+}
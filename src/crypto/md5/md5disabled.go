@@ -0,0 +1,14 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build nomd5
+
+package md5
+
+// Building with the nomd5 tag makes every use of the package panic, so
+// that a build can be shown not to compute MD5 anywhere, directly or in
+// a dependency. Programs that merely import the package still link and
+// start, and crypto.MD5.Available reports false so that callers which
+// check it fall back to another hash.
+const disabled = true
@@ -0,0 +1,48 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build nomd5
+
+package md5
+
+import (
+	"crypto"
+	"strings"
+	"testing"
+)
+
+// The other tests are excluded by the nomd5 tag. Run this one with
+//
+//	go test -tags nomd5 crypto/md5
+func TestDisabled(t *testing.T) {
+	for name, f := range map[string]func(){
+		"New":                   func() { New() },
+		"NewStrict":             func() { NewStrict() },
+		"NewResumable":          func() { NewResumable(nil) },
+		"NewFromState":          func() { NewFromState([4]uint32{}, 0) },
+		"NewCollisionDetecting": func() { NewCollisionDetecting() },
+		"Sum":                   func() { Sum(nil) },
+		"SumFile":               func() { SumFile("md5.go") },
+		"SumBatch":              func() { SumBatch(make([][]byte, 10)) },
+		"Compress":              func() { Compress(new([4]uint32), new([BlockSize]byte)) },
+		"Trace":                 func() { Trace(nil, func(int, uint32, uint32, uint32, uint32) {}) },
+		"Digest":                func() { new(Digest).Sum(nil) },
+	} {
+		func() {
+			defer func() {
+				msg, _ := recover().(string)
+				if !strings.Contains(msg, "nomd5") {
+					t.Errorf("%s: recovered %q, want a panic naming the nomd5 tag", name, msg)
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+func TestDisabledNotRegistered(t *testing.T) {
+	if crypto.MD5.Available() {
+		t.Error("crypto.MD5.Available() = true with the nomd5 tag")
+	}
+}
@@ -0,0 +1,10 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !nomd5
+
+package md5
+
+// disabled is set by the nomd5 build tag; see mustBeEnabled.
+const disabled = false
@@ -0,0 +1,159 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Multi-buffer hashing: several independent messages are compressed in
+// lock step, one message per lane of a vector register.
+
+package md5
+
+import "encoding/binary"
+
+// lanes is the number of messages blockMulti compresses at once.
+const lanes = 8
+
+// minLanes is the fewest busy lanes for which a blockMulti call is still
+// cheaper than finishing the remaining messages one at a time.
+const minLanes = lanes / 2
+
+// SumBatch returns the MD5 checksums of inputs, in order. The result is
+// the same as calling Sum on each input. Each MD5 computation is serial,
+// but on amd64 CPUs with AVX2 up to eight inputs are hashed in parallel
+// lanes, which is considerably faster for large numbers of inputs.
+func SumBatch(inputs [][]byte) [][Size]byte {
+	mustBeEnabled()
+	sums := make([][Size]byte, len(inputs))
+	if useMulti && len(inputs) >= minLanes {
+		sumMulti(inputs, sums)
+		return sums
+	}
+	for i, in := range inputs {
+		sums[i] = Sum(in)
+	}
+	return sums
+}
+
+// lane tracks the message being hashed in one lane.
+type lane struct {
+	idx  int    // index of the message in the batch, or -1 if idle
+	p    []byte // full blocks of the message not yet hashed
+	tail []byte // padded final blocks not yet hashed, a suffix of buf
+	buf  [2 * BlockSize]byte
+}
+
+// load sets up l to hash the message in, which has index idx.
+func (l *lane) load(in []byte, idx int) {
+	n := len(in) &^ (BlockSize - 1)
+	l.idx = idx
+	l.p = in[:n]
+	l.tail = padBlocks(&l.buf, in[n:], uint64(len(in)))
+}
+
+// next returns the next block of the message and reports whether it
+// is the last one.
+func (l *lane) next() (b []byte, last bool) {
+	if len(l.p) > 0 {
+		b, l.p = l.p[:BlockSize], l.p[BlockSize:]
+	} else {
+		b, l.tail = l.tail[:BlockSize], l.tail[BlockSize:]
+	}
+	return b, len(l.p) == 0 && len(l.tail) == 0
+}
+
+// sumMulti stores the MD5 checksum of each of inputs in the corresponding
+// element of sums, using blockMulti while at least minLanes messages are
+// in flight.
+func sumMulti(inputs [][]byte, sums [][Size]byte) {
+	var (
+		s      [4][lanes]uint32
+		w      [16][lanes]uint32
+		ls     [lanes]lane
+		done   [lanes]bool
+		next   int
+		active int
+	)
+	for j := range ls {
+		ls[j].idx = -1
+	}
+	for {
+		// Refill idle lanes.
+		for j := range ls {
+			if ls[j].idx >= 0 || next == len(inputs) {
+				continue
+			}
+			ls[j].load(inputs[next], next)
+			s[0][j], s[1][j], s[2][j], s[3][j] = init0, init1, init2, init3
+			next++
+			active++
+		}
+		if active < minLanes {
+			break
+		}
+
+		// Transpose one block of every busy lane into w. Idle lanes
+		// hash whatever is left over in w; their result is ignored.
+		for j := range ls {
+			if ls[j].idx < 0 {
+				continue
+			}
+			var b []byte
+			b, done[j] = ls[j].next()
+			for i := range w {
+				w[i][j] = binary.LittleEndian.Uint32(b[4*i:])
+			}
+		}
+		blockMulti(&s, &w)
+
+		for j := range ls {
+			if ls[j].idx < 0 || !done[j] {
+				continue
+			}
+			sum := &sums[ls[j].idx]
+			for i := range s {
+				binary.LittleEndian.PutUint32(sum[4*i:], s[i][j])
+			}
+			ls[j].idx = -1
+			active--
+		}
+	}
+
+	// Finish the stragglers one lane at a time.
+	for j := range ls {
+		l := &ls[j]
+		if l.idx < 0 {
+			continue
+		}
+		var d digest
+		for i := range d.s {
+			d.s[i] = s[i][j]
+		}
+		if len(l.p) > 0 {
+			block(&d, l.p)
+		}
+		block(&d, l.tail)
+		sum := &sums[l.idx]
+		for i := range d.s {
+			binary.LittleEndian.PutUint32(sum[4*i:], d.s[i])
+		}
+	}
+}
+
+// blockMultiGeneric compresses one block for each of the lanes messages
+// whose chaining values are in s, transposed so that s[i][j] is word i of
+// lane j. The message words are in w in the same layout.
+func blockMultiGeneric(s *[4][lanes]uint32, w *[16][lanes]uint32) {
+	var d digest
+	var p [BlockSize]byte
+	for j := 0; j < lanes; j++ {
+		for i := range d.s {
+			d.s[i] = s[i][j]
+		}
+		for i := range w {
+			binary.LittleEndian.PutUint32(p[4*i:], w[i][j])
+		}
+		blockGeneric(&d, p[:])
+		for i := range d.s {
+			s[i][j] = d.s[i]
+		}
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package md5
+
+import "internal/cpu"
+
+// useMulti reports whether SumBatch hashes in parallel lanes.
+var useMulti = cpu.X86.HasAVX2
+
+//go:noescape
+func blockMultiAVX2(s *[4][lanes]uint32, w *[16][lanes]uint32)
+
+func blockMulti(s *[4][lanes]uint32, w *[16][lanes]uint32) {
+	if useMulti {
+		blockMultiAVX2(s, w)
+	} else {
+		blockMultiGeneric(s, w)
+	}
+}
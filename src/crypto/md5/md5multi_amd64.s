@@ -0,0 +1,217 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+#include "textflag.h"
+
+// Multi-buffer MD5 block routine for AVX2. See blockMultiGeneric in
+// md5multi.go for the Go equivalent.
+//
+// Every YMM register holds the same state or message word of eight
+// independent messages, one message per 32-bit lane, so the algorithm
+// from RFC 1321 runs on all eight messages in lock step. AVX2 has no
+// vector rotate, so rotations are built from two shifts and an OR.
+
+#define W DI // message block
+
+#define T0 Y8
+#define T1 Y9
+#define ONES Y15
+
+// a = b + ((a + fn + X[x] + T[i]) <<< s), where fn, in T0, is the round
+// function of b, c and d. T1 is clobbered.
+#define STEP(a, b, x, i, s) \
+	VPADDD       (x*32)(W), a, a;      \
+	VPBROADCASTD md5T<>+(i*4)(SB), T1; \
+	VPADDD       T1, a, a;             \
+	VPADDD       T0, a, a;             \
+	VPSLLD       $(s), a, T1;          \
+	VPSRLD       $(32-s), a, a;        \
+	VPOR         T1, a, a;             \
+	VPADDD       b, a, a
+
+// F(b, c, d) = (b & c) | (^b & d), computed as ((c ^ d) & b) ^ d.
+#define ROUND1(a, b, c, d, x, i, s) \
+	VPXOR c, d, T0;  \
+	VPAND b, T0, T0; \
+	VPXOR d, T0, T0; \
+	STEP(a, b, x, i, s)
+
+// G(b, c, d) = (b & d) | (c & ^d), computed as ((b ^ c) & d) ^ c.
+#define ROUND2(a, b, c, d, x, i, s) \
+	VPXOR b, c, T0;  \
+	VPAND d, T0, T0; \
+	VPXOR c, T0, T0; \
+	STEP(a, b, x, i, s)
+
+// H(b, c, d) = b ^ c ^ d.
+#define ROUND3(a, b, c, d, x, i, s) \
+	VPXOR b, c, T0;  \
+	VPXOR d, T0, T0; \
+	STEP(a, b, x, i, s)
+
+// I(b, c, d) = c ^ (b | ^d).
+#define ROUND4(a, b, c, d, x, i, s) \
+	VPXOR d, ONES, T0; \
+	VPOR  b, T0, T0;   \
+	VPXOR c, T0, T0;   \
+	STEP(a, b, x, i, s)
+
+// func blockMultiAVX2(s *[4][lanes]uint32, w *[16][lanes]uint32)
+TEXT ·blockMultiAVX2(SB), NOSPLIT, $0-16
+	MOVQ s+0(FP), SI
+	MOVQ w+8(FP), W
+
+	VMOVDQU (0*32)(SI), Y0 // a
+	VMOVDQU (1*32)(SI), Y1 // b
+	VMOVDQU (2*32)(SI), Y2 // c
+	VMOVDQU (3*32)(SI), Y3 // d
+	VPCMPEQD ONES, ONES, ONES
+
+
+	ROUND1(Y0, Y1, Y2, Y3, 0, 0, 7)
+	ROUND1(Y3, Y0, Y1, Y2, 1, 1, 12)
+	ROUND1(Y2, Y3, Y0, Y1, 2, 2, 17)
+	ROUND1(Y1, Y2, Y3, Y0, 3, 3, 22)
+	ROUND1(Y0, Y1, Y2, Y3, 4, 4, 7)
+	ROUND1(Y3, Y0, Y1, Y2, 5, 5, 12)
+	ROUND1(Y2, Y3, Y0, Y1, 6, 6, 17)
+	ROUND1(Y1, Y2, Y3, Y0, 7, 7, 22)
+	ROUND1(Y0, Y1, Y2, Y3, 8, 8, 7)
+	ROUND1(Y3, Y0, Y1, Y2, 9, 9, 12)
+	ROUND1(Y2, Y3, Y0, Y1, 10, 10, 17)
+	ROUND1(Y1, Y2, Y3, Y0, 11, 11, 22)
+	ROUND1(Y0, Y1, Y2, Y3, 12, 12, 7)
+	ROUND1(Y3, Y0, Y1, Y2, 13, 13, 12)
+	ROUND1(Y2, Y3, Y0, Y1, 14, 14, 17)
+	ROUND1(Y1, Y2, Y3, Y0, 15, 15, 22)
+
+	ROUND2(Y0, Y1, Y2, Y3, 1, 16, 5)
+	ROUND2(Y3, Y0, Y1, Y2, 6, 17, 9)
+	ROUND2(Y2, Y3, Y0, Y1, 11, 18, 14)
+	ROUND2(Y1, Y2, Y3, Y0, 0, 19, 20)
+	ROUND2(Y0, Y1, Y2, Y3, 5, 20, 5)
+	ROUND2(Y3, Y0, Y1, Y2, 10, 21, 9)
+	ROUND2(Y2, Y3, Y0, Y1, 15, 22, 14)
+	ROUND2(Y1, Y2, Y3, Y0, 4, 23, 20)
+	ROUND2(Y0, Y1, Y2, Y3, 9, 24, 5)
+	ROUND2(Y3, Y0, Y1, Y2, 14, 25, 9)
+	ROUND2(Y2, Y3, Y0, Y1, 3, 26, 14)
+	ROUND2(Y1, Y2, Y3, Y0, 8, 27, 20)
+	ROUND2(Y0, Y1, Y2, Y3, 13, 28, 5)
+	ROUND2(Y3, Y0, Y1, Y2, 2, 29, 9)
+	ROUND2(Y2, Y3, Y0, Y1, 7, 30, 14)
+	ROUND2(Y1, Y2, Y3, Y0, 12, 31, 20)
+
+	ROUND3(Y0, Y1, Y2, Y3, 5, 32, 4)
+	ROUND3(Y3, Y0, Y1, Y2, 8, 33, 11)
+	ROUND3(Y2, Y3, Y0, Y1, 11, 34, 16)
+	ROUND3(Y1, Y2, Y3, Y0, 14, 35, 23)
+	ROUND3(Y0, Y1, Y2, Y3, 1, 36, 4)
+	ROUND3(Y3, Y0, Y1, Y2, 4, 37, 11)
+	ROUND3(Y2, Y3, Y0, Y1, 7, 38, 16)
+	ROUND3(Y1, Y2, Y3, Y0, 10, 39, 23)
+	ROUND3(Y0, Y1, Y2, Y3, 13, 40, 4)
+	ROUND3(Y3, Y0, Y1, Y2, 0, 41, 11)
+	ROUND3(Y2, Y3, Y0, Y1, 3, 42, 16)
+	ROUND3(Y1, Y2, Y3, Y0, 6, 43, 23)
+	ROUND3(Y0, Y1, Y2, Y3, 9, 44, 4)
+	ROUND3(Y3, Y0, Y1, Y2, 12, 45, 11)
+	ROUND3(Y2, Y3, Y0, Y1, 15, 46, 16)
+	ROUND3(Y1, Y2, Y3, Y0, 2, 47, 23)
+
+	ROUND4(Y0, Y1, Y2, Y3, 0, 48, 6)
+	ROUND4(Y3, Y0, Y1, Y2, 7, 49, 10)
+	ROUND4(Y2, Y3, Y0, Y1, 14, 50, 15)
+	ROUND4(Y1, Y2, Y3, Y0, 5, 51, 21)
+	ROUND4(Y0, Y1, Y2, Y3, 12, 52, 6)
+	ROUND4(Y3, Y0, Y1, Y2, 3, 53, 10)
+	ROUND4(Y2, Y3, Y0, Y1, 10, 54, 15)
+	ROUND4(Y1, Y2, Y3, Y0, 1, 55, 21)
+	ROUND4(Y0, Y1, Y2, Y3, 8, 56, 6)
+	ROUND4(Y3, Y0, Y1, Y2, 15, 57, 10)
+	ROUND4(Y2, Y3, Y0, Y1, 6, 58, 15)
+	ROUND4(Y1, Y2, Y3, Y0, 13, 59, 21)
+	ROUND4(Y0, Y1, Y2, Y3, 4, 60, 6)
+	ROUND4(Y3, Y0, Y1, Y2, 11, 61, 10)
+	ROUND4(Y2, Y3, Y0, Y1, 2, 62, 15)
+	ROUND4(Y1, Y2, Y3, Y0, 9, 63, 21)
+
+	VPADDD (0*32)(SI), Y0, Y0
+	VPADDD (1*32)(SI), Y1, Y1
+	VPADDD (2*32)(SI), Y2, Y2
+	VPADDD (3*32)(SI), Y3, Y3
+	VMOVDQU Y0, (0*32)(SI)
+	VMOVDQU Y1, (1*32)(SI)
+	VMOVDQU Y2, (2*32)(SI)
+	VMOVDQU Y3, (3*32)(SI)
+
+	VZEROUPPER
+	RET
+
+// The 64 additive constants T[i] of RFC 1321.
+DATA md5T<>+0(SB)/4, $0xd76aa478
+DATA md5T<>+4(SB)/4, $0xe8c7b756
+DATA md5T<>+8(SB)/4, $0x242070db
+DATA md5T<>+12(SB)/4, $0xc1bdceee
+DATA md5T<>+16(SB)/4, $0xf57c0faf
+DATA md5T<>+20(SB)/4, $0x4787c62a
+DATA md5T<>+24(SB)/4, $0xa8304613
+DATA md5T<>+28(SB)/4, $0xfd469501
+DATA md5T<>+32(SB)/4, $0x698098d8
+DATA md5T<>+36(SB)/4, $0x8b44f7af
+DATA md5T<>+40(SB)/4, $0xffff5bb1
+DATA md5T<>+44(SB)/4, $0x895cd7be
+DATA md5T<>+48(SB)/4, $0x6b901122
+DATA md5T<>+52(SB)/4, $0xfd987193
+DATA md5T<>+56(SB)/4, $0xa679438e
+DATA md5T<>+60(SB)/4, $0x49b40821
+DATA md5T<>+64(SB)/4, $0xf61e2562
+DATA md5T<>+68(SB)/4, $0xc040b340
+DATA md5T<>+72(SB)/4, $0x265e5a51
+DATA md5T<>+76(SB)/4, $0xe9b6c7aa
+DATA md5T<>+80(SB)/4, $0xd62f105d
+DATA md5T<>+84(SB)/4, $0x2441453
+DATA md5T<>+88(SB)/4, $0xd8a1e681
+DATA md5T<>+92(SB)/4, $0xe7d3fbc8
+DATA md5T<>+96(SB)/4, $0x21e1cde6
+DATA md5T<>+100(SB)/4, $0xc33707d6
+DATA md5T<>+104(SB)/4, $0xf4d50d87
+DATA md5T<>+108(SB)/4, $0x455a14ed
+DATA md5T<>+112(SB)/4, $0xa9e3e905
+DATA md5T<>+116(SB)/4, $0xfcefa3f8
+DATA md5T<>+120(SB)/4, $0x676f02d9
+DATA md5T<>+124(SB)/4, $0x8d2a4c8a
+DATA md5T<>+128(SB)/4, $0xfffa3942
+DATA md5T<>+132(SB)/4, $0x8771f681
+DATA md5T<>+136(SB)/4, $0x6d9d6122
+DATA md5T<>+140(SB)/4, $0xfde5380c
+DATA md5T<>+144(SB)/4, $0xa4beea44
+DATA md5T<>+148(SB)/4, $0x4bdecfa9
+DATA md5T<>+152(SB)/4, $0xf6bb4b60
+DATA md5T<>+156(SB)/4, $0xbebfbc70
+DATA md5T<>+160(SB)/4, $0x289b7ec6
+DATA md5T<>+164(SB)/4, $0xeaa127fa
+DATA md5T<>+168(SB)/4, $0xd4ef3085
+DATA md5T<>+172(SB)/4, $0x4881d05
+DATA md5T<>+176(SB)/4, $0xd9d4d039
+DATA md5T<>+180(SB)/4, $0xe6db99e5
+DATA md5T<>+184(SB)/4, $0x1fa27cf8
+DATA md5T<>+188(SB)/4, $0xc4ac5665
+DATA md5T<>+192(SB)/4, $0xf4292244
+DATA md5T<>+196(SB)/4, $0x432aff97
+DATA md5T<>+200(SB)/4, $0xab9423a7
+DATA md5T<>+204(SB)/4, $0xfc93a039
+DATA md5T<>+208(SB)/4, $0x655b59c3
+DATA md5T<>+212(SB)/4, $0x8f0ccc92
+DATA md5T<>+216(SB)/4, $0xffeff47d
+DATA md5T<>+220(SB)/4, $0x85845dd1
+DATA md5T<>+224(SB)/4, $0x6fa87e4f
+DATA md5T<>+228(SB)/4, $0xfe2ce6e0
+DATA md5T<>+232(SB)/4, $0xa3014314
+DATA md5T<>+236(SB)/4, $0x4e0811a1
+DATA md5T<>+240(SB)/4, $0xf7537e82
+DATA md5T<>+244(SB)/4, $0xbd3af235
+DATA md5T<>+248(SB)/4, $0x2ad7d2bb
+DATA md5T<>+252(SB)/4, $0xeb86d391
+GLOBL md5T<>(SB), RODATA, $256
@@ -0,0 +1,91 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !nomd5
+
+package md5
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+func batchInputs(n int) [][]byte {
+	sizes := []int{0, 1, 3, 55, 56, 57, 63, 64, 65, 119, 120, 128, 200, 1000}
+	buf := make([]byte, 1000)
+	rand.Read(buf)
+	inputs := make([][]byte, n)
+	for i := range inputs {
+		inputs[i] = buf[i%7 : i%7+sizes[i%len(sizes)]%(len(buf)-7)]
+	}
+	return inputs
+}
+
+func TestSumBatch(t *testing.T) {
+	for _, n := range []int{0, 1, 3, 4, 7, 8, 9, 15, 16, 17, 100} {
+		inputs := batchInputs(n)
+		sums := SumBatch(inputs)
+		if len(sums) != n {
+			t.Fatalf("SumBatch returned %d checksums for %d inputs", len(sums), n)
+		}
+		for i, in := range inputs {
+			if want := Sum(in); sums[i] != want {
+				t.Errorf("SumBatch(%d inputs)[%d] (len %d) = %x, want %x", n, i, len(in), sums[i], want)
+			}
+		}
+
+		// Exercise the lane scheduler even where SumBatch does not use it.
+		sums = make([][Size]byte, n)
+		sumMulti(inputs, sums)
+		for i, in := range inputs {
+			if want := Sum(in); sums[i] != want {
+				t.Errorf("sumMulti(%d inputs)[%d] (len %d) = %x, want %x", n, i, len(in), sums[i], want)
+			}
+		}
+	}
+}
+
+// Tests that blockMultiGeneric and blockMulti (in assembly for amd64) match.
+func TestBlockMultiGeneric(t *testing.T) {
+	var s [4][lanes]uint32
+	var w [16][lanes]uint32
+	for i := range s {
+		for j := range s[i] {
+			s[i][j] = uint32(i*lanes+j) * 0x9e3779b9
+		}
+	}
+	for i := range w {
+		for j := range w[i] {
+			w[i][j] = uint32(i*lanes+j) * 0x85ebca6b
+		}
+	}
+	sGen, wGen := s, w
+	blockMultiGeneric(&sGen, &wGen)
+	blockMulti(&s, &w)
+	if s != sGen {
+		t.Errorf("blockMulti and blockMultiGeneric resulted in different states")
+	}
+}
+
+func benchmarkBatchSize(b *testing.B, size int, batch bool) {
+	inputs := make([][]byte, 1024)
+	for i := range inputs {
+		inputs[i] = buf[:size]
+	}
+	b.SetBytes(int64(size * len(inputs)))
+	for i := 0; i < b.N; i++ {
+		if batch {
+			SumBatch(inputs)
+		} else {
+			for _, in := range inputs {
+				Sum(in)
+			}
+		}
+	}
+}
+
+func BenchmarkSumBatch32Bytes(b *testing.B) { benchmarkBatchSize(b, 32, true) }
+func BenchmarkSumLoop32Bytes(b *testing.B)  { benchmarkBatchSize(b, 32, false) }
+func BenchmarkSumBatch1K(b *testing.B)      { benchmarkBatchSize(b, 1024, true) }
+func BenchmarkSumLoop1K(b *testing.B)       { benchmarkBatchSize(b, 1024, false) }
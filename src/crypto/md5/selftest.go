@@ -0,0 +1,64 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package md5
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+)
+
+// selfTests are known-answer tests from RFC 1321, section A.5, and the
+// common one-million-a test. The message is in repeated count times.
+var selfTests = []struct {
+	in    string
+	count int
+	out   string
+}{
+	{"", 1, "\xd4\x1d\x8c\xd9\x8f\x00\xb2\x04\xe9\x80\x09\x98\xec\xf8\x42\x7e"},
+	{"a", 1, "\x0c\xc1\x75\xb9\xc0\xf1\xb6\xa8\x31\xc3\x99\xe2\x69\x77\x26\x61"},
+	{"abc", 1, "\x90\x01\x50\x98\x3c\xd2\x4f\xb0\xd6\x96\x3f\x7d\x28\xe1\x7f\x72"},
+	{"message digest", 1, "\xf9\x6b\x69\x7d\x7c\xb7\x93\x8d\x52\x5a\x2f\x31\xaa\xf1\x61\xd0"},
+	{"abcdefghijklmnopqrstuvwxyz", 1, "\xc3\xfc\xd3\xd7\x61\x92\xe4\x00\x7d\xfb\x49\x6c\xca\x67\xe1\x3b"},
+	{"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789", 1, "\xd1\x74\xab\x98\xd2\x77\xd9\xf5\xa5\x61\x1c\x2c\x9f\x41\x9d\x9f"},
+	{"12345678901234567890123456789012345678901234567890123456789012345678901234567890", 1, "\x57\xed\xf4\xa2\x2b\xe3\xc9\x55\xac\x49\xda\x2e\x21\x07\xb6\x7a"},
+	{"a", 1000000, "\x77\x07\xd6\xae\x4e\x02\x7c\x70\xee\xa2\xa9\x35\xc2\x29\x6f\x21"},
+}
+
+// SelfTest runs known-answer tests, including multi-block messages,
+// through the same block function, assembly or not, that the hash
+// returned by New uses. Each message is hashed once with a single Write
+// and once in short writes that straddle block boundaries. SelfTest
+// returns an error naming the first message that fails; a non-nil result
+// means that this build of the package computes wrong checksums.
+func SelfTest() error {
+	for _, tt := range selfTests {
+		msg := bytes.Repeat([]byte(tt.in), tt.count)
+		for _, step := range []int{len(msg), 7} {
+			var d digest
+			d.Reset()
+			for p := msg; len(p) > 0; {
+				n := step
+				if n > len(p) {
+					n = len(p)
+				}
+				d.Write(p[:n])
+				p = p[n:]
+			}
+			sum := d.checkSum()
+			if string(sum[:]) != tt.out {
+				name := tt.in
+				if len(name) > 16 {
+					name = name[:16] + "..."
+				}
+				if tt.count > 1 {
+					name += " (repeated)"
+				}
+				return errors.New("crypto/md5: self-test failed for message " + strconv.Quote(name))
+			}
+		}
+	}
+	return nil
+}
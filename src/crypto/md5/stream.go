@@ -0,0 +1,68 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package md5
+
+import (
+	"crypto/internal/hashio"
+	"io"
+	"time"
+)
+
+// bufSize is the size of the buffer the streaming helpers read into.
+const bufSize = hashio.BufSize
+
+// HashStats describes where the time went while hashing a reader.
+type HashStats struct {
+	BytesRead int64         // bytes read and hashed
+	Reads     int           // calls to Read, including the one returning io.EOF or an error
+	ReadTime  time.Duration // time spent in Read
+	HashTime  time.Duration // time spent hashing, including finalization
+	MaxRead   int           // largest number of bytes returned by one Read
+}
+
+// SumWithStats returns the MD5 checksum of the data read from r and
+// statistics that show whether hashing r was bound by I/O or by CPU. On a
+// read error other than io.EOF the zero checksum is returned with the
+// statistics gathered so far.
+func SumWithStats(r io.Reader) (sum [Size]byte, stats HashStats, err error) {
+	d := new(digest)
+	d.Reset()
+	var s hashio.Stats
+	if err := hashio.ReadWithStats(r, d, &s); err != nil {
+		return sum, HashStats(s), err
+	}
+	start := time.Now()
+	sum = d.checkSum()
+	s.HashTime += time.Since(start)
+	return sum, HashStats(s), nil
+}
+
+// SumSplit reads r to EOF and returns the MD5 checksum of each record in
+// it, as separated by delim. The delimiters are not hashed, and a final
+// record without one is included. Records are hashed as they are read,
+// so they need not fit in memory. On a read error other than io.EOF the
+// checksums of the records completed so far are returned.
+func SumSplit(r io.Reader, delim byte) (digests [][Size]byte, err error) {
+	d := new(digest)
+	d.Reset()
+	err = hashio.Split(r, delim, d, func() {
+		digests = append(digests, d.checkSum())
+		d.Reset()
+	})
+	return digests, err
+}
+
+// SumFile returns the MD5 checksum of the contents of the named file and
+// its size in bytes, such as for checking an MD5SUMS manifest. The file is
+// read in large block-aligned chunks and always closed. The first error
+// from opening, reading or closing the file is returned.
+func SumFile(path string) (sum [Size]byte, size int64, err error) {
+	d := new(digest)
+	d.Reset()
+	if size, err = hashio.ReadFile(path, d); err != nil {
+		return sum, size, err
+	}
+	return d.checkSum(), size, nil
+}
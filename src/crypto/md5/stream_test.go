@@ -0,0 +1,158 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !nomd5
+
+package md5
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestSumWithStats(t *testing.T) {
+	data := make([]byte, 3*bufSize+17)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	want := Sum(data)
+
+	sum, stats, err := SumWithStats(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("SumWithStats: %v", err)
+	}
+	if sum != want {
+		t.Errorf("SumWithStats = %x, want %x", sum, want)
+	}
+	if stats.BytesRead != int64(len(data)) {
+		t.Errorf("BytesRead = %d, want %d", stats.BytesRead, len(data))
+	}
+	if stats.Reads != 5 {
+		t.Errorf("Reads = %d, want 5", stats.Reads)
+	}
+	if stats.MaxRead != bufSize {
+		t.Errorf("MaxRead = %d, want %d", stats.MaxRead, bufSize)
+	}
+
+	sum, stats, err = SumWithStats(iotest.OneByteReader(bytes.NewReader(data[:100])))
+	if err != nil {
+		t.Fatalf("SumWithStats: %v", err)
+	}
+	if want := Sum(data[:100]); sum != want {
+		t.Errorf("SumWithStats = %x, want %x", sum, want)
+	}
+	if stats.Reads != 101 || stats.MaxRead != 1 {
+		t.Errorf("Reads, MaxRead = %d, %d, want 101, 1", stats.Reads, stats.MaxRead)
+	}
+
+	errRead := errors.New("read failed")
+	r := io.MultiReader(bytes.NewReader(data[:10]), iotest.ErrReader(errRead))
+	if _, stats, err = SumWithStats(r); err != errRead {
+		t.Errorf("SumWithStats error = %v, want %v", err, errRead)
+	}
+	if stats.BytesRead != 10 {
+		t.Errorf("BytesRead = %d, want 10", stats.BytesRead)
+	}
+}
+
+func TestSumSplit(t *testing.T) {
+	long := bytes.Repeat([]byte("x"), 2*bufSize+5)
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a\n", []string{"a"}},
+		{"a\nb", []string{"a", "b"}},
+		{"a\n\nb\n", []string{"a", "", "b"}},
+		{"\n", []string{""}},
+		{"a\n" + string(long) + "\nc", []string{"a", string(long), "c"}},
+	}
+	for _, tt := range tests {
+		for _, r := range []io.Reader{strings.NewReader(tt.in), iotest.HalfReader(strings.NewReader(tt.in))} {
+			digests, err := SumSplit(r, '\n')
+			if err != nil {
+				t.Fatalf("SumSplit: %v", err)
+			}
+			if len(digests) != len(tt.want) {
+				t.Fatalf("SumSplit(%.20q) returned %d digests, want %d", tt.in, len(digests), len(tt.want))
+			}
+			for i, rec := range tt.want {
+				if want := Sum([]byte(rec)); digests[i] != want {
+					t.Errorf("SumSplit(%.20q)[%d] = %x, want %x", tt.in, i, digests[i], want)
+				}
+			}
+		}
+	}
+
+	errRead := errors.New("read failed")
+	r := io.MultiReader(strings.NewReader("a\x00b"), iotest.ErrReader(errRead))
+	digests, err := SumSplit(r, 0)
+	if err != errRead {
+		t.Errorf("SumSplit error = %v, want %v", err, errRead)
+	}
+	if len(digests) != 1 || digests[0] != Sum([]byte("a")) {
+		t.Errorf("SumSplit returned %x before error, want checksum of %q", digests, "a")
+	}
+}
+
+func TestSumFile(t *testing.T) {
+	dir := t.TempDir()
+
+	data := make([]byte, 3<<20+17)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	path := filepath.Join(dir, "data")
+	if err := os.WriteFile(path, data, 0666); err != nil {
+		t.Fatal(err)
+	}
+	sum, size, err := SumFile(path)
+	if err != nil {
+		t.Fatalf("SumFile: %v", err)
+	}
+	if want := Sum(data); sum != want {
+		t.Errorf("SumFile = %x, want %x", sum, want)
+	}
+	if size != int64(len(data)) {
+		t.Errorf("SumFile size = %d, want %d", size, len(data))
+	}
+
+	// A small file is read with a buffer sized to fit it.
+	small := filepath.Join(dir, "small")
+	if err := os.WriteFile(small, data[:100], 0666); err != nil {
+		t.Fatal(err)
+	}
+	sum, size, err = SumFile(small)
+	if err != nil {
+		t.Fatalf("SumFile(small): %v", err)
+	}
+	if want := Sum(data[:100]); sum != want || size != 100 {
+		t.Errorf("SumFile(small) = %x, %d, want %x, 100", sum, size, want)
+	}
+
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	sum, size, err = SumFile(empty)
+	if err != nil {
+		t.Fatalf("SumFile(empty): %v", err)
+	}
+	if s := fmt.Sprintf("%x", sum); s != "d41d8cd98f00b204e9800998ecf8427e" || size != 0 {
+		t.Errorf("SumFile(empty) = %s, %d, want the empty digest and 0", s, size)
+	}
+
+	if _, _, err := SumFile(filepath.Join(dir, "missing")); !os.IsNotExist(err) {
+		t.Errorf("SumFile(missing) error = %v, want a not-exist error", err)
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package md5
+
+import "encoding/binary"
+
+// Trace returns the MD5 checksum of data, the same as Sum, and calls fn
+// after each of the 64 steps of every block the compression function
+// processes, including the padding blocks. fn receives the step number,
+// from 0 to 63 within the block, and the four state words a, b, c and d
+// after the step. Step s belongs to round s/16 + 1.
+//
+// Trace is meant for studying and visualizing the algorithm. It always
+// runs the generic Go block function, traced, whatever the CPU supports,
+// and is much slower than Sum.
+func Trace(data []byte, fn func(step int, a, b, c, d uint32)) [Size]byte {
+	mustBeEnabled()
+	var d digest
+	d.Reset()
+	n := len(data) &^ (BlockSize - 1)
+	blockTrace(&d, data[:n], fn)
+
+	var buf [2 * BlockSize]byte
+	blockTrace(&d, padBlocks(&buf, data[n:], uint64(len(data))), fn)
+
+	var sum [Size]byte
+	for i, s := range d.s {
+		binary.LittleEndian.PutUint32(sum[4*i:], s)
+	}
+	return sum
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha1
+
+import (
+	"bytes"
+	"errors"
+)
+
+// selfTests are known-answer tests from FIPS 180-2, appendix A, and the
+// SHA-1 checksum of the empty string. The message is in repeated count
+// times.
+var selfTests = []struct {
+	name  string
+	in    string
+	count int
+	out   string
+}{
+	{
+		"abc", "abc", 1,
+		"\xa9\x99\x3e\x36\x47\x06\x81\x6a\xba\x3e\x25\x71\x78\x50\xc2\x6c" +
+			"\x9c\xd0\xd8\x9d",
+	},
+	{
+		"empty string", "", 1,
+		"\xda\x39\xa3\xee\x5e\x6b\x4b\x0d\x32\x55\xbf\xef\x95\x60\x18\x90" +
+			"\xaf\xd8\x07\x09",
+	},
+	{
+		"448-bit message", "abcdbcdecdefdefgefghfghighijhijkijkljklmklmnlmnomnopnopq", 1,
+		"\x84\x98\x3e\x44\x1c\x3b\xd2\x6e\xba\xae\x4a\xa1\xf9\x51\x29\xe5" +
+			"\xe5\x46\x70\xf1",
+	},
+	{
+		"one million a", "a", 1000000,
+		"\x34\xaa\x97\x3c\xd4\xc4\xda\xa4\xf6\x1e\xeb\x2b\xdb\xad\x27\x31" +
+			"\x65\x34\x01\x6f",
+	},
+}
+
+// SelfTest runs known-answer tests, including multi-block messages,
+// through the same block function, assembly or not, that the hash
+// returned by New uses. Each message is hashed once with a single Write
+// and once in short writes that straddle block boundaries. SelfTest
+// returns an error naming the first test that fails; a non-nil result
+// means that this build of the package computes wrong checksums.
+func SelfTest() error {
+	for _, tt := range selfTests {
+		msg := bytes.Repeat([]byte(tt.in), tt.count)
+		for _, step := range []int{len(msg), 7} {
+			var d digest
+			d.Reset()
+			for p := msg; len(p) > 0; {
+				n := step
+				if n > len(p) {
+					n = len(p)
+				}
+				d.Write(p[:n])
+				p = p[n:]
+			}
+			sum := d.checkSum()
+			if string(sum[:]) != tt.out {
+				return errors.New("crypto/sha1: self-test failed: " + tt.name)
+			}
+		}
+	}
+	return nil
+}
@@ -128,6 +128,11 @@ func (d *digest) Size() int { return Size }
 
 func (d *digest) BlockSize() int { return BlockSize }
 
+// Len returns the number of bytes written to the hash since it was last
+// reset. For a hash restored with UnmarshalBinary, the count includes the
+// bytes covered by the restored state.
+func (d *digest) Len() uint64 { return d.len }
+
 func (d *digest) Write(p []byte) (nn int, err error) {
 	//获取写入字节数，更新d.len的值
 	nn = len(p)
@@ -140,6 +140,34 @@ func TestBlockSize(t *testing.T) {
 }
 
 // Tests that blockGeneric (pure Go) and block (in assembly for some architectures) match.
+func TestLen(t *testing.T) {
+	for _, newHash := range []func() hash.Hash{New} {
+		h := newHash().(interface {
+			hash.Hash
+			Len() uint64
+		})
+		if n := h.Len(); n != 0 {
+			t.Errorf("Len of a new hash = %d, want 0", n)
+		}
+		for _, n := range []int{1, 63, 64, 1000} {
+			want := h.Len() + uint64(n)
+			h.Write(make([]byte, n))
+			if got := h.Len(); got != want {
+				t.Errorf("Len after writing %d more bytes = %d, want %d", n, got, want)
+			}
+		}
+		before := h.Len()
+		h.Sum(nil)
+		if got := h.Len(); got != before {
+			t.Errorf("Len after Sum = %d, want %d", got, before)
+		}
+		h.Reset()
+		if n := h.Len(); n != 0 {
+			t.Errorf("Len after Reset = %d, want 0", n)
+		}
+	}
+}
+
 func TestBlockGeneric(t *testing.T) {
 	for i := 1; i < 30; i++ { // arbitrary factor
 		gen, asm := New().(*digest), New().(*digest)
@@ -210,6 +238,20 @@ func TestLargeHashes(t *testing.T) {
 	}
 }
 
+func TestSelfTest(t *testing.T) {
+	if err := SelfTest(); err != nil {
+		t.Fatalf("SelfTest() = %v", err)
+	}
+
+	// A wrong known answer must be reported.
+	saved := selfTests[0].out
+	defer func() { selfTests[0].out = saved }()
+	selfTests[0].out = "\x00" + saved[1:]
+	if err := SelfTest(); err == nil {
+		t.Error("SelfTest() with a corrupted known answer = nil")
+	}
+}
+
 var bench = New()
 var buf = make([]byte, 8192)
 
@@ -0,0 +1,47 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha256
+
+// A Builder computes a SHA256 checksum from parts added by chained calls,
+// such as Hash256().AddString("a").Add(b).Sum(). The result is the same as
+// writing the parts in order to a hash returned by New. The zero value is
+// not ready to use; call Hash256.
+//
+// Add and AddString panic with ErrMessageTooLong if the data would exceed
+// the longest message SHA256 is defined for.
+type Builder struct {
+	d digest
+}
+
+// Hash256 returns a new Builder.
+func Hash256() *Builder {
+	b := new(Builder)
+	b.d.Reset()
+	return b
+}
+
+// Add adds p to the data being hashed and returns b.
+func (b *Builder) Add(p []byte) *Builder {
+	if _, err := b.d.Write(p); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// AddString adds s to the data being hashed and returns b. Unlike
+// Add([]byte(s)), it does not copy s into a new slice.
+func (b *Builder) AddString(s string) *Builder {
+	if _, err := b.d.WriteString(s); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Sum returns the SHA256 checksum of the data added so far. It does not
+// change b, so more parts can still be added.
+func (b *Builder) Sum() [Size]byte {
+	d := b.d
+	return d.checkSum()
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha256
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	long := strings.Repeat("0123456789", 30)
+	parts := []string{"", "a", "bc", long[:63], long[:64], long[:65], long}
+	for i := range parts {
+		for j := range parts {
+			want := New()
+			want.Write([]byte(parts[i]))
+			want.Write([]byte(parts[j]))
+			want.Write([]byte(parts[i]))
+
+			b := Hash256().AddString(parts[i]).Add([]byte(parts[j])).AddString(parts[i])
+			if got := b.Sum(); !bytes.Equal(got[:], want.Sum(nil)) {
+				t.Errorf("Builder over parts of %d, %d and %d bytes = %x, want %x",
+					len(parts[i]), len(parts[j]), len(parts[i]), got, want.Sum(nil))
+			}
+
+			// Sum must not disturb the running state.
+			want.Write([]byte("more"))
+			if got := b.AddString("more").Sum(); !bytes.Equal(got[:], want.Sum(nil)) {
+				t.Errorf("Builder after Sum = %x, want %x", got, want.Sum(nil))
+			}
+		}
+	}
+}
+
+func TestBuilderTooLong(t *testing.T) {
+	for name, add := range map[string]func(*Builder){
+		"Add":       func(b *Builder) { b.Add([]byte("x")) },
+		"AddString": func(b *Builder) { b.AddString("x") },
+	} {
+		b := Hash256()
+		b.d.len = maxMessageLen
+		func() {
+			defer func() {
+				if err := recover(); err != ErrMessageTooLong {
+					t.Errorf("%s past the length limit: recovered %v, want ErrMessageTooLong", name, err)
+				}
+			}()
+			add(b)
+		}()
+		if b.d.len != maxMessageLen {
+			t.Errorf("%s past the length limit changed the length to %d", name, b.d.len)
+		}
+	}
+}
+
+func TestBuilderAllocations(t *testing.T) {
+	long := strings.Repeat("x", 1000)
+	p := []byte(long)
+	if n := testing.AllocsPerRun(10, func() {
+		Hash256().AddString(long).Add(p).AddString("a").Sum()
+	}); n > 0 {
+		t.Errorf("Builder allocates %v times, want 0", n)
+	}
+}
@@ -6,6 +6,7 @@ package sha256_test
 
 import (
 	"crypto/sha256"
+	"encoding"
 	"fmt"
 	"io"
 	"log"
@@ -39,3 +40,34 @@ func ExampleNew_file() {
 
 	fmt.Printf("%x", h.Sum(nil))
 }
+
+func ExampleSum256Code() {
+	code := sha256.Sum256Code([]byte("hello world\n"), 8)
+	fmt.Println(code)
+	// Output: -QAQ1/OG
+}
+
+func ExampleNew_checkpoint() {
+	// A long-running job stores the state of its hash in a text record, so
+	// that it can be resumed, for example after a restart.
+	h := sha256.New()
+	h.Write([]byte("hello "))
+	checkpoint, err := h.(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		log.Fatal(err)
+	}
+	record := "upload-1234 " + string(checkpoint)
+
+	// Later, the job resumes from the record.
+	var id, state string
+	if _, err := fmt.Sscan(record, &id, &state); err != nil {
+		log.Fatal(err)
+	}
+	resumed := sha256.New()
+	if err := resumed.(encoding.TextUnmarshaler).UnmarshalText([]byte(state)); err != nil {
+		log.Fatal(err)
+	}
+	resumed.Write([]byte("world\n"))
+	fmt.Printf("%s: %x", id, resumed.Sum(nil))
+	// Output: upload-1234: a948904f2f0f479b8f8197694b30184b0d2ed1c1cd2a1ec0fb85d299a192a447
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha256
+
+import "errors"
+
+// fingerprintPrefix starts every fingerprint in the OpenSSH format.
+const fingerprintPrefix = "SHA256:"
+
+// base64Alphabet is the standard base64 alphabet of RFC 4648. It is
+// spelled out because this package cannot depend on encoding/base64.
+const base64Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// fingerprintLen is the length of a fingerprint: the prefix followed by
+// the 256-bit checksum in base64 without padding.
+const fingerprintLen = len(fingerprintPrefix) + (8*Size+5)/6
+
+var errFingerprint = errors.New("crypto/sha256: invalid fingerprint")
+
+// Fingerprint returns the SHA256 fingerprint of data in the format that
+// OpenSSH uses for public keys, "SHA256:" followed by the checksum in
+// standard base64 without padding. For an SSH public key, data is the key
+// in the wire format, that is, the base64-decoded second field of a line
+// in authorized_keys.
+func Fingerprint(data []byte) string {
+	sum := Sum256(data)
+	b := make([]byte, fingerprintLen)
+	copy(b, fingerprintPrefix)
+	dst := b[len(fingerprintPrefix):]
+	src := sum[:]
+	for len(src) >= 3 {
+		v := uint(src[0])<<16 | uint(src[1])<<8 | uint(src[2])
+		dst[0] = base64Alphabet[v>>18&0x3f]
+		dst[1] = base64Alphabet[v>>12&0x3f]
+		dst[2] = base64Alphabet[v>>6&0x3f]
+		dst[3] = base64Alphabet[v&0x3f]
+		src, dst = src[3:], dst[4:]
+	}
+	// Size is 2 mod 3, which leaves 16 bits for three digits.
+	v := uint(src[0])<<16 | uint(src[1])<<8
+	dst[0] = base64Alphabet[v>>18&0x3f]
+	dst[1] = base64Alphabet[v>>12&0x3f]
+	dst[2] = base64Alphabet[v>>6&0x3f]
+	return string(b)
+}
+
+// ParseFingerprint returns the checksum in a fingerprint produced by
+// Fingerprint. It returns an error if s does not start with "SHA256:", is
+// not followed by exactly the 43 base64 digits of a 256-bit checksum, or
+// if the unused low bits of the last digit are not zero, so that every
+// checksum has exactly one valid fingerprint.
+func ParseFingerprint(s string) ([Size]byte, error) {
+	var sum [Size]byte
+	if len(s) != fingerprintLen || s[:len(fingerprintPrefix)] != fingerprintPrefix {
+		return sum, errFingerprint
+	}
+	src := s[len(fingerprintPrefix):]
+	dst := sum[:]
+	var v, bits uint
+	for i := 0; i < len(src); i++ {
+		d := base64Digit(src[i])
+		if d < 0 {
+			return [Size]byte{}, errFingerprint
+		}
+		v = v<<6 | uint(d)
+		bits += 6
+		if bits >= 8 {
+			bits -= 8
+			dst[0] = byte(v >> bits)
+			dst = dst[1:]
+		}
+	}
+	if v&(1<<bits-1) != 0 {
+		return [Size]byte{}, errFingerprint
+	}
+	return sum, nil
+}
+
+// base64Digit returns the value of the base64 digit c, or -1 if c is not
+// in base64Alphabet.
+func base64Digit(c byte) int {
+	switch {
+	case 'A' <= c && c <= 'Z':
+		return int(c - 'A')
+	case 'a' <= c && c <= 'z':
+		return int(c-'a') + 26
+	case '0' <= c && c <= '9':
+		return int(c-'0') + 52
+	case c == '+':
+		return 62
+	case c == '/':
+		return 63
+	}
+	return -1
+}
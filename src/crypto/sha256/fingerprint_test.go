@@ -0,0 +1,66 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha256
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+var fingerprintTests = []struct {
+	in  string // hexadecimal
+	out string
+}{
+	{"", "SHA256:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU"},
+	{"616263", "SHA256:ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0"},
+	// An Ed25519 public key and the fingerprint ssh-keygen -l reports for it.
+	{
+		"0000000b7373682d65643235353139000000207052819513b6a4c39ae9f1aedb2d614af79749e251763bf493e714d0e5427785",
+		"SHA256:V3SjDKPU/Sg3yWXBryCRESLhpfdCE3c+XFcwIZo9J6Y",
+	},
+}
+
+func TestFingerprint(t *testing.T) {
+	for _, tt := range fingerprintTests {
+		in, _ := hex.DecodeString(tt.in)
+		if got := Fingerprint(in); got != tt.out {
+			t.Errorf("Fingerprint(%s) = %s, want %s", tt.in, got, tt.out)
+		}
+		sum, err := ParseFingerprint(tt.out)
+		if err != nil {
+			t.Errorf("ParseFingerprint(%s): %v", tt.out, err)
+		} else if sum != Sum256(in) {
+			t.Errorf("ParseFingerprint(%s) = %x, want %x", tt.out, sum, Sum256(in))
+		}
+	}
+}
+
+func TestParseFingerprintInvalid(t *testing.T) {
+	valid := fingerprintTests[0].out
+	for _, s := range []string{
+		"",
+		"SHA256:",
+		valid[:len(valid)-1],
+		valid + "=",
+		valid + "A",
+		"sha256:" + valid[7:],
+		"MD5:" + valid[4:],
+		valid[:20] + "-" + valid[21:],
+		valid[:20] + "_" + valid[21:],
+		valid[:len(valid)-1] + "V", // nonzero unused bits
+		strings.Replace(valid, ":", " ", 1),
+	} {
+		if _, err := ParseFingerprint(s); err == nil {
+			t.Errorf("ParseFingerprint(%q): no error when one was expected", s)
+		}
+	}
+}
+
+func BenchmarkFingerprint(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Fingerprint(buf[:32])
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha256
+
+import (
+	"errors"
+	"hash"
+)
+
+// NewHMAC returns a new hash.Hash computing HMAC-SHA256 (RFC 2104) with
+// the given key. It produces the same output as crypto/hmac.New(New, key),
+// but the key is processed once: the states after hashing the inner and
+// outer padded key blocks are kept, and Reset restores the inner state
+// without hashing the key again. Keys longer than BlockSize are first
+// hashed down to Size bytes, as RFC 2104 requires.
+func NewHMAC(key []byte) hash.Hash {
+	return newHMAC(key)
+}
+
+func newHMAC(key []byte) *hmacDigest {
+	if len(key) > BlockSize {
+		sum := Sum256(key)
+		key = sum[:]
+	}
+	var ipad, opad [BlockSize]byte
+	copy(ipad[:], key)
+	copy(opad[:], key)
+	for i := range ipad {
+		ipad[i] ^= 0x36
+		opad[i] ^= 0x5c
+	}
+	h := new(hmacDigest)
+	h.inner.Reset()
+	h.inner.Write(ipad[:])
+	h.outer.Reset()
+	h.outer.Write(opad[:])
+	h.Reset()
+	return h
+}
+
+// hmacDigest computes HMAC-SHA256 from precomputed padded key states.
+type hmacDigest struct {
+	inner digest // state after hashing key XOR ipad
+	outer digest // state after hashing key XOR opad
+	d     digest // inner hash of the message written so far
+}
+
+func (h *hmacDigest) Size() int { return Size }
+
+func (h *hmacDigest) BlockSize() int { return BlockSize }
+
+func (h *hmacDigest) Write(p []byte) (nn int, err error) {
+	return h.d.Write(p)
+}
+
+func (h *hmacDigest) Sum(in []byte) []byte {
+	d := h.d
+	sum := d.checkSum()
+	o := h.outer
+	o.Write(sum[:])
+	sum = o.checkSum()
+	return append(in, sum[:]...)
+}
+
+func (h *hmacDigest) Reset() {
+	h.d = h.inner
+}
+
+// Expand implements the HKDF-Expand step of RFC 5869 with HMAC-SHA256. It
+// derives length bytes of output keying material from the pseudorandom
+// key prk and the optional context info. The output of HKDF-Expand is
+// limited to 255 blocks, so an error is returned if length is greater
+// than 255*Size or negative.
+func Expand(prk, info []byte, length int) ([]byte, error) {
+	if length < 0 || length > 255*Size {
+		return nil, errors.New("crypto/sha256: invalid HKDF output length")
+	}
+	h := newHMAC(prk)
+	okm := make([]byte, 0, length+Size)
+	var t []byte // T(i-1); T(0) is empty
+	for i := byte(1); len(okm) < length; i++ {
+		h.Reset()
+		h.Write(t)
+		h.Write(info)
+		h.Write([]byte{i})
+		okm = h.Sum(okm)
+		t = okm[len(okm)-Size:]
+	}
+	return okm[:length], nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha256
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/hex"
+	"testing"
+)
+
+func TestNewHMAC(t *testing.T) {
+	msg := make([]byte, 3*BlockSize+5)
+	for i := range msg {
+		msg[i] = byte(i)
+	}
+	for _, keyLen := range []int{0, 1, 20, BlockSize - 1, BlockSize, BlockSize + 1, 200} {
+		key := bytes.Repeat([]byte{0xa5}, keyLen)
+		for _, n := range []int{0, 1, BlockSize, len(msg)} {
+			want := hmac.New(New, key)
+			want.Write(msg[:n])
+
+			h := NewHMAC(key)
+			h.Write(msg[:n])
+			if got := h.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+				t.Errorf("NewHMAC(%d byte key) over %d bytes = %x, want %x", keyLen, n, got, want.Sum(nil))
+			}
+
+			// Sum must not disturb the running state, and Reset must
+			// restore the keyed initial state.
+			h.Write([]byte("more"))
+			want.Write([]byte("more"))
+			if got := h.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+				t.Errorf("NewHMAC(%d byte key) after Sum = %x, want %x", keyLen, got, want.Sum(nil))
+			}
+			h.Reset()
+			want.Reset()
+			h.Write(msg[:n])
+			want.Write(msg[:n])
+			if got := h.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+				t.Errorf("NewHMAC(%d byte key) after Reset = %x, want %x", keyLen, got, want.Sum(nil))
+			}
+		}
+	}
+}
+
+func TestNewHMACRFC4231(t *testing.T) {
+	// RFC 4231, test case 2.
+	h := NewHMAC([]byte("Jefe"))
+	h.Write([]byte("what do ya want for nothing?"))
+	want := []byte{
+		0x5b, 0xdc, 0xc1, 0x46, 0xbf, 0x60, 0x75, 0x4e,
+		0x6a, 0x04, 0x24, 0x26, 0x08, 0x95, 0x75, 0xc7,
+		0x5a, 0x00, 0x3f, 0x08, 0x9d, 0x27, 0x39, 0x83,
+		0x9d, 0xec, 0x58, 0xb9, 0x64, 0xec, 0x38, 0x43,
+	}
+	if got := h.Sum(nil); !bytes.Equal(got, want) {
+		t.Errorf("HMAC-SHA256 = %x, want %x", got, want)
+	}
+}
+
+func BenchmarkNewHMAC(b *testing.B) {
+	key := make([]byte, 32)
+	h := NewHMAC(key)
+	sum := make([]byte, Size)
+	b.SetBytes(1024)
+	for i := 0; i < b.N; i++ {
+		h.Reset()
+		h.Write(buf[:1024])
+		h.Sum(sum[:0])
+	}
+}
+
+func TestExpand(t *testing.T) {
+	// RFC 5869, Appendix A.1 to A.3.
+	tests := []struct {
+		prk, info string
+		okm       string
+	}{
+		{
+			"077709362c2e32df0ddc3f0dc47bba6390b6c73bb50f9c3122ec844ad7c2b3e5",
+			"f0f1f2f3f4f5f6f7f8f9",
+			"3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865",
+		},
+		{
+			"06a6b88c5853361a06104c9ceb35b45cef760014904671014a193f40c15fc244",
+			"b0b1b2b3b4b5b6b7b8b9babbbcbdbebfc0c1c2c3c4c5c6c7c8c9cacbcccdcecf" +
+				"d0d1d2d3d4d5d6d7d8d9dadbdcdddedfe0e1e2e3e4e5e6e7e8e9eaebecedeeef" +
+				"f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff",
+			"b11e398dc80327a1c8e7f78c596a49344f012eda2d4efad8a050cc4c19afa97c" +
+				"59045a99cac7827271cb41c65e590e09da3275600c2f09b8367793a9aca3db71" +
+				"cc30c58179ec3e87c14c01d5c1f3434f1d87",
+		},
+		{
+			"19ef24a32c717b167f33a91d6f648bdf96596776afdb6377ac434c1c293ccb04",
+			"",
+			"8da4e775a563c18f715f802a063c5a31b8a11f5c5ee1879ec3454e5f3c738d2d9d201395faa4b61a96c8",
+		},
+	}
+	for i, tt := range tests {
+		prk, _ := hex.DecodeString(tt.prk)
+		info, _ := hex.DecodeString(tt.info)
+		want, _ := hex.DecodeString(tt.okm)
+		okm, err := Expand(prk, info, len(want))
+		if err != nil {
+			t.Fatalf("#%d: Expand: %v", i, err)
+		}
+		if !bytes.Equal(okm, want) {
+			t.Errorf("#%d: Expand = %x, want %x", i, okm, want)
+		}
+		// A shorter output is a prefix of a longer one.
+		okm, _ = Expand(prk, info, 5)
+		if !bytes.Equal(okm, want[:5]) {
+			t.Errorf("#%d: Expand of 5 bytes = %x, want %x", i, okm, want[:5])
+		}
+	}
+
+	if okm, err := Expand([]byte("prk"), nil, 255*Size); err != nil || len(okm) != 255*Size {
+		t.Errorf("Expand of 255*Size bytes = %d bytes, %v", len(okm), err)
+	}
+	for _, length := range []int{-1, 255*Size + 1} {
+		if _, err := Expand([]byte("prk"), nil, length); err == nil {
+			t.Errorf("Expand of %d bytes: no error when one was expected", length)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha256
+
+import (
+	"crypto/internal/hashenc"
+	"errors"
+	"strconv"
+)
+
+// The JSON form of the hash state is
+//
+//	{"alg":"sha256","h":["6a09e667",...],"x":"616263","nx":3,"len":3}
+//
+// alg is "sha256" or "sha224", h holds the eight chaining words in
+// hexadecimal, x the nx buffered bytes of the partial block in
+// hexadecimal, and len the number of bytes written. The fields may appear
+// in any order but all of them are required. It is encoded and decoded by
+// hand because this package cannot depend on encoding/json.
+
+var errJSONState = errors.New("crypto/sha256: invalid JSON hash state")
+
+// MarshalJSON encodes the state of the hash as a JSON object that can be
+// inspected by people and restored with UnmarshalJSON.
+func (d *digest) MarshalJSON() ([]byte, error) {
+	alg := "sha256"
+	if d.is224 {
+		alg = "sha224"
+	}
+	b := make([]byte, 0, 256)
+	b = append(b, `{"alg":"`...)
+	b = append(b, alg...)
+	b = append(b, `","h":[`...)
+	for i, x := range d.h {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		var w [4]byte
+		var text [8]byte
+		w[0], w[1], w[2], w[3] = byte(x>>24), byte(x>>16), byte(x>>8), byte(x)
+		hashenc.EncodeHex(text[:], w[:])
+		b = append(b, '"')
+		b = append(b, text[:]...)
+		b = append(b, '"')
+	}
+	b = append(b, `],"x":"`...)
+	text := make([]byte, 2*d.nx)
+	hashenc.EncodeHex(text, d.x[:d.nx])
+	b = append(b, text...)
+	b = append(b, `","nx":`...)
+	b = strconv.AppendInt(b, int64(d.nx), 10)
+	b = append(b, `,"len":`...)
+	b = strconv.AppendUint(b, d.len, 10)
+	b = append(b, '}')
+	return b, nil
+}
+
+// UnmarshalJSON restores the state encoded by MarshalJSON. It rejects
+// states of unknown algorithms, SHA224 states for a SHA256 hash and vice
+// versa with a *StateMismatchError, and states longer than the longest
+// message SHA256 is defined for with ErrMessageTooLong.
+func (d *digest) UnmarshalJSON(b []byte) error {
+	if d.frozen {
+		return errors.New("crypto/sha256: unmarshal into frozen hash")
+	}
+	var (
+		alg     string
+		h       [8]uint32
+		x       []byte
+		nx, n   uint64
+		seen    = make(map[string]bool)
+		s       = jsonScanner{b: b}
+		partial [chunk]byte
+	)
+	s.expect('{')
+	for s.err == nil {
+		key := s.str()
+		s.expect(':')
+		if seen[key] {
+			return errJSONState
+		}
+		seen[key] = true
+		switch key {
+		case "alg":
+			alg = s.str()
+		case "h":
+			s.expect('[')
+			for i := range h {
+				if i > 0 {
+					s.expect(',')
+				}
+				word := s.hex()
+				if len(word) != 4 {
+					return errJSONState
+				}
+				h[i] = uint32(word[0])<<24 | uint32(word[1])<<16 | uint32(word[2])<<8 | uint32(word[3])
+			}
+			s.expect(']')
+		case "x":
+			x = s.hex()
+		case "nx":
+			nx = s.uint()
+		case "len":
+			n = s.uint()
+		default:
+			return errors.New("crypto/sha256: unknown JSON hash state field " + strconv.Quote(key))
+		}
+		if !s.consume(',') {
+			break
+		}
+	}
+	s.expect('}')
+	s.end()
+	if s.err != nil || len(seen) != 5 {
+		return errJSONState
+	}
+	switch {
+	case alg != "sha256" && alg != "sha224":
+		return errors.New("crypto/sha256: unknown hash state algorithm " + strconv.Quote(alg))
+	case (alg == "sha224") != d.is224:
+		return &StateMismatchError{Alg: alg}
+	case uint64(len(x)) != nx || nx != n%chunk:
+		return errors.New("crypto/sha256: inconsistent JSON hash state")
+	case n > maxMessageLen:
+		return ErrMessageTooLong
+	}
+	copy(partial[:], x)
+	d.h = h
+	d.x = partial
+	d.nx = int(nx)
+	d.len = n
+	return nil
+}
+
+// jsonScanner reads the few JSON constructs used by the hash state. After
+// the first error all methods do nothing and return zero values.
+type jsonScanner struct {
+	b   []byte
+	err error
+}
+
+func (s *jsonScanner) skipSpace() {
+	for len(s.b) > 0 && (s.b[0] == ' ' || s.b[0] == '\t' || s.b[0] == '\n' || s.b[0] == '\r') {
+		s.b = s.b[1:]
+	}
+}
+
+// consume skips c, if it is the next token, and reports whether it did.
+func (s *jsonScanner) consume(c byte) bool {
+	s.skipSpace()
+	if s.err != nil || len(s.b) == 0 || s.b[0] != c {
+		return false
+	}
+	s.b = s.b[1:]
+	return true
+}
+
+func (s *jsonScanner) expect(c byte) {
+	if !s.consume(c) {
+		s.err = errJSONState
+	}
+}
+
+// str reads a string without escape sequences.
+func (s *jsonScanner) str() string {
+	if !s.consume('"') {
+		s.err = errJSONState
+		return ""
+	}
+	for i, c := range s.b {
+		if c == '"' {
+			str := string(s.b[:i])
+			s.b = s.b[i+1:]
+			return str
+		}
+		if c == '\\' || c < ' ' {
+			break
+		}
+	}
+	s.err = errJSONState
+	return ""
+}
+
+// hex reads a string of hexadecimal digits and returns its value.
+func (s *jsonScanner) hex() []byte {
+	str := s.str()
+	if s.err != nil {
+		return nil
+	}
+	b, ok := hashenc.DecodeHex([]byte(str))
+	if !ok {
+		s.err = errJSONState
+		return nil
+	}
+	return b
+}
+
+// uint reads a non-negative integer.
+func (s *jsonScanner) uint() uint64 {
+	s.skipSpace()
+	if s.err != nil {
+		return 0
+	}
+	i := 0
+	for i < len(s.b) && '0' <= s.b[i] && s.b[i] <= '9' {
+		i++
+	}
+	n, err := strconv.ParseUint(string(s.b[:i]), 10, 64)
+	if err != nil {
+		s.err = errJSONState
+		return 0
+	}
+	s.b = s.b[i:]
+	return n
+}
+
+// end checks that nothing but white space is left.
+func (s *jsonScanner) end() {
+	s.skipSpace()
+	if len(s.b) > 0 {
+		s.err = errJSONState
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha256
+
+import (
+	"bytes"
+	"encoding/json"
+	"hash"
+	"strings"
+	"testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	h := New()
+	h.Write([]byte("abc"))
+	b, err := json.Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"alg":"sha256","h":["6a09e667","bb67ae85","3c6ef372","a54ff53a",` +
+		`"510e527f","9b05688c","1f83d9ab","5be0cd19"],"x":"616263","nx":3,"len":3}`
+	if string(b) != want {
+		t.Errorf("json.Marshal = %s, want %s", b, want)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	for _, newHash := range []func() hash.Hash{New, New224} {
+		for _, n := range []int{0, 1, 63, 64, 65, 200} {
+			h := newHash()
+			h.Write(data[:n])
+			b, err := json.Marshal(h)
+			if err != nil {
+				t.Fatal(err)
+			}
+			h2 := newHash()
+			if err := json.Unmarshal(b, h2); err != nil {
+				t.Fatalf("json.Unmarshal(%s): %v", b, err)
+			}
+			h.Write(data[n:])
+			h2.Write(data[n:])
+			if got, want := h2.Sum(nil), h.Sum(nil); !bytes.Equal(got, want) {
+				t.Errorf("hash after JSON round trip at %d bytes = %x, want %x", n, got, want)
+			}
+		}
+	}
+}
+
+func TestUnmarshalJSONErrors(t *testing.T) {
+	const good = `{"alg":"sha256","h":["6a09e667","bb67ae85","3c6ef372","a54ff53a",` +
+		`"510e527f","9b05688c","1f83d9ab","5be0cd19"],"x":"616263","nx":3,"len":3}`
+	if err := New().(json.Unmarshaler).UnmarshalJSON([]byte(good)); err != nil {
+		t.Fatalf("UnmarshalJSON(%s): %v", good, err)
+	}
+	spaced := strings.NewReplacer(":", " : ", ",", ",\n\t").Replace(good)
+	if err := New().(json.Unmarshaler).UnmarshalJSON([]byte(spaced)); err != nil {
+		t.Errorf("UnmarshalJSON(%s): %v", spaced, err)
+	}
+	if err := New224().(json.Unmarshaler).UnmarshalJSON([]byte(good)); err == nil {
+		t.Error("UnmarshalJSON of a SHA256 state into a SHA224 hash: no error")
+	}
+
+	bad := []struct{ old, new string }{
+		{`"sha256"`, `"sha512"`},
+		{`"sha256"`, `"sha224"`},
+		{`"6a09e667"`, `"6a09e66"`},
+		{`"6a09e667"`, `"6a09e66z"`},
+		{`"6a09e667",`, ``},
+		{`"616263"`, `"6162"`},
+		{`"nx":3`, `"nx":2`},
+		{`"len":3`, `"len":68`},
+		{`"len":3`, `"len":-3`},
+		{`"len":3`, `"len":18446744073709551555`}, // past the length limit
+		{`,"len":3`, ``},
+		{`"len":3`, `"len":3,"len":3`},
+		{`"len":3`, `"len":3,"y":1`},
+		{`}`, `}x`},
+		{`{`, ``},
+	}
+	for _, tt := range bad {
+		s := strings.Replace(good, tt.old, tt.new, 1)
+		if err := New().(json.Unmarshaler).UnmarshalJSON([]byte(s)); err == nil {
+			t.Errorf("UnmarshalJSON(%s): no error when one was expected", s)
+		}
+	}
+
+	long := strings.Replace(good, `"len":3`, `"len":18446744073709551555`, 1)
+	if err := New().(json.Unmarshaler).UnmarshalJSON([]byte(long)); err != ErrMessageTooLong {
+		t.Errorf("UnmarshalJSON(%s) = %v, want %v", long, err, ErrMessageTooLong)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha256
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+)
+
+// OpenSSL keeps the state of a SHA-224 or SHA-256 hash in a SHA256_CTX,
+//
+//	typedef struct SHA256state_st {
+//		SHA_LONG h[8];
+//		SHA_LONG Nl, Nh;
+//		SHA_LONG data[SHA_LBLOCK];
+//		unsigned int num, md_len;
+//	} SHA256_CTX;
+//
+// where SHA_LONG and unsigned int are 32 bits wide on all platforms Go
+// supports, so the struct has no padding. The words are in the byte order
+// of the machine. Nl and Nh are the low and high halves of the number of
+// bits hashed, data holds the num buffered bytes of the partial block as
+// bytes, and md_len is the checksum size: 32 for SHA-256, 28 for SHA-224.
+
+// OpenSSLStateSize is the size of an OpenSSL SHA256_CTX in bytes.
+const OpenSSLStateSize = 112
+
+var errOpenSSLState = errors.New("crypto/sha256: invalid OpenSSL SHA256_CTX")
+
+// MarshalOpenSSL returns the state of the hash laid out as an OpenSSL
+// SHA256_CTX in the byte order order, which is that of the machine running
+// the C code, binary.LittleEndian on amd64 and arm64. Copying the result
+// over a SHA256_CTX lets SHA256_Update and SHA256_Final, or the SHA-224
+// equivalents, continue the hash.
+func (d *digest) MarshalOpenSSL(order binary.ByteOrder) []byte {
+	b := make([]byte, OpenSSLStateSize)
+	for i, h := range d.h {
+		order.PutUint32(b[4*i:], h)
+	}
+	order.PutUint32(b[32:], uint32(d.len<<3))
+	order.PutUint32(b[36:], uint32(d.len>>29))
+	copy(b[40:40+chunk], d.x[:d.nx])
+	order.PutUint32(b[104:], uint32(d.nx))
+	if d.is224 {
+		order.PutUint32(b[108:], Size224)
+	} else {
+		order.PutUint32(b[108:], Size)
+	}
+	return b
+}
+
+// NewFromOpenSSL returns a new hash.Hash that continues the hash whose
+// state is the OpenSSL SHA256_CTX ctx, in the byte order order, as written
+// by MarshalOpenSSL or copied out of a C program. The result computes the
+// SHA224 checksum if md_len is 28 and the SHA256 checksum if it is 32.
+// An error is returned if ctx is not OpenSSLStateSize bytes long, if
+// md_len or num is out of range, if the bit count is not a whole number of
+// bytes, or if num does not match the bit count.
+func NewFromOpenSSL(ctx []byte, order binary.ByteOrder) (hash.Hash, error) {
+	if len(ctx) != OpenSSLStateSize {
+		return nil, errOpenSSLState
+	}
+	d := new(digest)
+	switch order.Uint32(ctx[108:]) {
+	case Size:
+	case Size224:
+		d.is224 = true
+	default:
+		return nil, errOpenSSLState
+	}
+	bits := uint64(order.Uint32(ctx[36:]))<<32 | uint64(order.Uint32(ctx[32:]))
+	num := order.Uint32(ctx[104:])
+	if bits%8 != 0 || num >= chunk || uint64(num) != bits/8%chunk {
+		return nil, errOpenSSLState
+	}
+	for i := range d.h {
+		d.h[i] = order.Uint32(ctx[4*i:])
+	}
+	d.len = bits / 8
+	d.nx = copy(d.x[:], ctx[40:40+num])
+	return d, nil
+}
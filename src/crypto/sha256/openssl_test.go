@@ -0,0 +1,126 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha256
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"testing"
+)
+
+type openSSLMarshaler interface {
+	MarshalOpenSSL(binary.ByteOrder) []byte
+}
+
+// The states of SHA256_CTX values dumped from a little-endian C program
+// using OpenSSL after SHA256_Update of bytes 0 to 99 and SHA224_Update of
+// "abc".
+var openSSLTests = []struct {
+	is224 bool
+	in    []byte
+	ctx   string
+}{
+	{
+		false,
+		seq(100),
+		"dfa299fc7a2af48880d1b97ba2c6cd335f755602509a5b9d31cca944a784be5a" +
+			"2003000000000000" +
+			"404142434445464748494a4b4c4d4e4f505152535455565758595a5b5c5d5e5f" +
+			"6061626300000000000000000000000000000000000000000000000000000000" +
+			"2400000020000000",
+	},
+	{
+		true,
+		[]byte("abc"),
+		"d89e05c107d57c3617dd703039590ef7310bc0ff11155868a78ff964a44ffabe" +
+			"1800000000000000" +
+			"6162630000000000000000000000000000000000000000000000000000000000" +
+			"0000000000000000000000000000000000000000000000000000000000000000" +
+			"030000001c000000",
+	},
+}
+
+func seq(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return b
+}
+
+func TestMarshalOpenSSL(t *testing.T) {
+	for _, tt := range openSSLTests {
+		h := New()
+		if tt.is224 {
+			h = New224()
+		}
+		h.Write(tt.in)
+		got := h.(openSSLMarshaler).MarshalOpenSSL(binary.LittleEndian)
+		if want, _ := hex.DecodeString(tt.ctx); !bytes.Equal(got, want) {
+			t.Errorf("MarshalOpenSSL after %d bytes =\n%x\nwant\n%x", len(tt.in), got, want)
+		}
+	}
+}
+
+func TestNewFromOpenSSL(t *testing.T) {
+	suffix := []byte("and some more")
+	for _, tt := range openSSLTests {
+		ctx, _ := hex.DecodeString(tt.ctx)
+		h, err := NewFromOpenSSL(ctx, binary.LittleEndian)
+		if err != nil {
+			t.Fatalf("NewFromOpenSSL after %d bytes: %v", len(tt.in), err)
+		}
+		var want hash.Hash = New()
+		if tt.is224 {
+			want = New224()
+		}
+		want.Write(tt.in)
+		want.Write(suffix)
+		h.Write(suffix)
+		if got, want := h.Sum(nil), want.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("NewFromOpenSSL after %d bytes: checksum %x, want %x", len(tt.in), got, want)
+		}
+	}
+
+	// Round trips in both byte orders, across block boundaries.
+	data := seq(200)
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		for _, n := range []int{0, 1, 63, 64, 65, 130} {
+			h := New()
+			h.Write(data[:n])
+			h2, err := NewFromOpenSSL(h.(openSSLMarshaler).MarshalOpenSSL(order), order)
+			if err != nil {
+				t.Fatalf("%v: NewFromOpenSSL after %d bytes: %v", order, n, err)
+			}
+			h2.Write(data[n:])
+			if got, want := h2.Sum(nil), Sum256(data); !bytes.Equal(got, want[:]) {
+				t.Errorf("%v: round trip after %d bytes: checksum %x, want %x", order, n, got, want)
+			}
+		}
+	}
+}
+
+func TestNewFromOpenSSLInvalid(t *testing.T) {
+	valid, _ := hex.DecodeString(openSSLTests[0].ctx)
+	tests := []struct {
+		name   string
+		modify func(b []byte) []byte
+	}{
+		{"short", func(b []byte) []byte { return b[:OpenSSLStateSize-1] }},
+		{"long", func(b []byte) []byte { return append(b, 0) }},
+		{"md_len", func(b []byte) []byte { binary.LittleEndian.PutUint32(b[108:], 20); return b }},
+		{"num too large", func(b []byte) []byte { binary.LittleEndian.PutUint32(b[104:], 64); return b }},
+		{"num mismatch", func(b []byte) []byte { binary.LittleEndian.PutUint32(b[104:], 35); return b }},
+		{"partial byte", func(b []byte) []byte { b[32] |= 1; return b }},
+	}
+	for _, tt := range tests {
+		b := tt.modify(append([]byte(nil), valid...))
+		if _, err := NewFromOpenSSL(b, binary.LittleEndian); err == nil {
+			t.Errorf("NewFromOpenSSL with invalid %s: no error when one was expected", tt.name)
+		}
+	}
+}
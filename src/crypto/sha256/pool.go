@@ -0,0 +1,38 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha256
+
+import (
+	"hash"
+	"sync"
+)
+
+var digestPool = sync.Pool{
+	New: func() interface{} { return new(digest) },
+}
+
+// Get returns a hash.Hash computing the SHA256 checksum, like New, but
+// reuses a hash previously released with Put when one is available.
+func Get() hash.Hash {
+	d := digestPool.Get().(*digest)
+	d.Reset()
+	return d
+}
+
+// Put releases h, which must not be used afterwards, so that Get can
+// reuse it. The state of h, including any buffered input, is zeroed
+// first so that no data lingers in the pool. Put takes any plain SHA256
+// or SHA224 digest of this package, such as the hashes returned by New,
+// New224, Get and NewFromState and by their Clone methods; Get resets it
+// to SHA256. Other hashes, such as those of NewStrict, NewCounting or
+// NewHMAC, are ignored.
+func Put(h hash.Hash) {
+	d, ok := h.(*digest)
+	if !ok {
+		return
+	}
+	*d = digest{}
+	digestPool.Put(d)
+}
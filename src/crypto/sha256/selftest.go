@@ -0,0 +1,85 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha256
+
+import (
+	"bytes"
+	"errors"
+)
+
+// selfTests are known-answer tests, mostly from FIPS 180-2 and its
+// SHA-224 change notice. The message is in repeated count times.
+var selfTests = []struct {
+	name  string
+	is224 bool
+	in    string
+	count int
+	out   string
+}{
+	{
+		"abc", false, "abc", 1,
+		"\xba\x78\x16\xbf\x8f\x01\xcf\xea\x41\x41\x40\xde\x5d\xae\x22\x23" +
+			"\xb0\x03\x61\xa3\x96\x17\x7a\x9c\xb4\x10\xff\x61\xf2\x00\x15\xad",
+	},
+	{
+		"empty string", false, "", 1,
+		"\xe3\xb0\xc4\x42\x98\xfc\x1c\x14\x9a\xfb\xf4\xc8\x99\x6f\xb9\x24" +
+			"\x27\xae\x41\xe4\x64\x9b\x93\x4c\xa4\x95\x99\x1b\x78\x52\xb8\x55",
+	},
+	{
+		"448-bit message", false, "abcdbcdecdefdefgefghfghighijhijkijkljklmklmnlmnomnopnopq", 1,
+		"\x24\x8d\x6a\x61\xd2\x06\x38\xb8\xe5\xc0\x26\x93\x0c\x3e\x60\x39" +
+			"\xa3\x3c\xe4\x59\x64\xff\x21\x67\xf6\xec\xed\xd4\x19\xdb\x06\xc1",
+	},
+	{
+		"one million a", false, "a", 1000000,
+		"\xcd\xc7\x6e\x5c\x99\x14\xfb\x92\x81\xa1\xc7\xe2\x84\xd7\x3e\x67" +
+			"\xf1\x80\x9a\x48\xa4\x97\x20\x0e\x04\x6d\x39\xcc\xc7\x11\x2c\xd0",
+	},
+	{
+		"896-bit message", false, "abcdefghbcdefghicdefghijdefghijkefghijklfghijklmghijklmnhijklmnoijklmnopjklmnopqklmnopqrlmnopqrsmnopqrstnopqrstu", 1,
+		"\xcf\x5b\x16\xa7\x78\xaf\x83\x80\x03\x6c\xe5\x9e\x7b\x04\x92\x37" +
+			"\x0b\x24\x9b\x11\xe8\xf0\x7a\x51\xaf\xac\x45\x03\x7a\xfe\xe9\xd1",
+	},
+	{
+		"SHA-224 abc", true, "abc", 1,
+		"\x23\x09\x7d\x22\x34\x05\xd8\x22\x86\x42\xa4\x77\xbd\xa2\x55\xb3" +
+			"\x2a\xad\xbc\xe4\xbd\xa0\xb3\xf7\xe3\x6c\x9d\xa7",
+	},
+	{
+		"SHA-224 448-bit message", true, "abcdbcdecdefdefgefghfghighijhijkijkljklmklmnlmnomnopnopq", 1,
+		"\x75\x38\x8b\x16\x51\x27\x76\xcc\x5d\xba\x5d\xa1\xfd\x89\x01\x50" +
+			"\xb0\xc6\x45\x5c\xb4\xf5\x8b\x19\x52\x52\x25\x25",
+	},
+}
+
+// SelfTest runs known-answer tests, including multi-block messages,
+// through the same block function, assembly or not, that the hashes
+// returned by New and New224 use. Each message is hashed once with a
+// single Write and once in short writes that straddle block boundaries.
+// SelfTest returns an error naming the first test that fails; a non-nil
+// result means that this build of the package computes wrong checksums.
+func SelfTest() error {
+	for _, tt := range selfTests {
+		msg := bytes.Repeat([]byte(tt.in), tt.count)
+		for _, step := range []int{len(msg), 7} {
+			d := digest{is224: tt.is224}
+			d.Reset()
+			for p := msg; len(p) > 0; {
+				n := step
+				if n > len(p) {
+					n = len(p)
+				}
+				d.Write(p[:n])
+				p = p[n:]
+			}
+			sum := d.checkSum()
+			if string(sum[:len(tt.out)]) != tt.out {
+				return errors.New("crypto/sha256: self-test failed: " + tt.name)
+			}
+		}
+	}
+	return nil
+}
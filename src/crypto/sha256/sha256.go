@@ -8,9 +8,15 @@ package sha256
 
 import (
 	"crypto"
+	"crypto/internal/hashenc"
+	"crypto/subtle"
 	"encoding/binary"
 	"errors"
 	"hash"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 func init() {
@@ -27,6 +33,15 @@ const Size224 = 28
 // The blocksize of SHA256 and SHA224 in bytes.
 const BlockSize = 64
 
+// maxMessageLen is the length in bytes of the longest message FIPS 180-4
+// allows: fewer than 2^64 bits.
+const maxMessageLen = 1<<61 - 1
+
+// ErrMessageTooLong is returned by Write once the data written would
+// exceed the longest message SHA-256 is defined for, 2^64-1 bits. The
+// length would otherwise wrap around and produce a wrong checksum.
+var ErrMessageTooLong = errors.New("crypto/sha256: message exceeds 2^64-1 bits")
+
 const (
 	chunk     = 64
 	init0     = 0x6A09E667
@@ -54,16 +69,38 @@ type digest struct {
 	nx    int
 	len   uint64
 	is224 bool // mark if this digest is SHA-224
+
+	frozen bool // Write, Reset and UnmarshalBinary are disabled; see Freeze
+
+	// blocks, if not nil, counts the blocks compressed; see NewCounting.
+	// Copies of the digest made to finalize it share the counter.
+	blocks *uint64
+}
+
+// block compresses p, a multiple of chunk bytes long, into d.h.
+func (d *digest) block(p []byte) {
+	if d.blocks != nil {
+		*d.blocks += uint64(len(p) / chunk)
+	}
+	block(d, p)
 }
 
 const (
-	magic224      = "sha\x02"
-	magic256      = "sha\x03"
-	marshaledSize = len(magic256) + 8*4 + chunk + 8
+	magic224 = "sha\x02"
+	magic256 = "sha\x03"
+
+	// The compact encoding of MarshalCompact.
+	magic224Compact   = "sha\x82"
+	magic256Compact   = "sha\x83"
+	compactHeaderSize = len(magic256Compact) + 8*4 + 8
 )
 
+// MarshaledSize is the length of the hash state returned by the
+// MarshalBinary method of the hashes returned by New and New224.
+const MarshaledSize = len(magic256) + 8*4 + chunk + 8
+
 func (d *digest) MarshalBinary() ([]byte, error) {
-	b := make([]byte, 0, marshaledSize)
+	b := make([]byte, 0, MarshaledSize)
 	if d.is224 {
 		b = append(b, magic224...)
 	} else {
@@ -83,13 +120,79 @@ func (d *digest) MarshalBinary() ([]byte, error) {
 	return b, nil
 }
 
+// MarshalCompact returns the hash state like MarshalBinary, but in an
+// encoding that only holds the bytes of the partial block that are in
+// use, so it is between 44 and 107 bytes long instead of MarshaledSize.
+// UnmarshalBinary and ParseState accept both encodings.
+func (d *digest) MarshalCompact() ([]byte, error) {
+	b := make([]byte, 0, compactHeaderSize+d.nx)
+	if d.is224 {
+		b = append(b, magic224Compact...)
+	} else {
+		b = append(b, magic256Compact...)
+	}
+	for _, h := range d.h {
+		b = appendUint32(b, h)
+	}
+	b = appendUint64(b, d.len)
+	return append(b, d.x[:d.nx]...), nil
+}
+
+// A StateMismatchError is returned when a hash state of one algorithm is
+// restored into a hash of the other, such as a SHA224 state into a hash
+// returned by New. Code that restores states of either algorithm can use
+// Alg to pick New or New224 and try again.
+type StateMismatchError struct {
+	Alg string // the algorithm of the state, "sha224" or "sha256"
+}
+
+func (e *StateMismatchError) Error() string {
+	other := "sha256"
+	if e.Alg == "sha256" {
+		other = "sha224"
+	}
+	return "crypto/sha256: cannot restore " + e.Alg + " hash state into " + other + " hash"
+}
+
+// checkMagic returns an error unless magic identifies a state of the
+// algorithm of d, where magic224 and magic256 are the identifiers of the
+// two algorithms in the encoding being decoded.
+func (d *digest) checkMagic(magic, magic224, magic256 string) error {
+	switch {
+	case magic == magic224 && !d.is224:
+		return &StateMismatchError{Alg: "sha224"}
+	case magic == magic256 && d.is224:
+		return &StateMismatchError{Alg: "sha256"}
+	case magic != magic224 && magic != magic256:
+		return errors.New("crypto/sha256: invalid hash state identifier")
+	}
+	return nil
+}
+
+// UnmarshalBinary restores a state returned by MarshalBinary or
+// MarshalCompact. If the state belongs to the other algorithm, SHA224
+// instead of SHA256 or the other way around, the error is a
+// *StateMismatchError. A state that records more than the longest message
+// SHA256 is defined for is refused with ErrMessageTooLong.
 func (d *digest) UnmarshalBinary(b []byte) error {
-	if len(b) < len(magic224) || (d.is224 && string(b[:len(magic224)]) != magic224) || (!d.is224 && string(b[:len(magic256)]) != magic256) {
+	if d.frozen {
+		return errors.New("crypto/sha256: unmarshal into frozen hash")
+	}
+	if len(b) >= len(magic256Compact) && (string(b[:len(magic256Compact)]) == magic224Compact || string(b[:len(magic256Compact)]) == magic256Compact) {
+		return d.unmarshalCompact(b)
+	}
+	if len(b) < len(magic224) {
 		return errors.New("crypto/sha256: invalid hash state identifier")
 	}
-	if len(b) != marshaledSize {
+	if err := d.checkMagic(string(b[:len(magic224)]), magic224, magic256); err != nil {
+		return err
+	}
+	if len(b) != MarshaledSize {
 		return errors.New("crypto/sha256: invalid hash state size")
 	}
+	if _, n := consumeUint64(b[MarshaledSize-8:]); n > maxMessageLen {
+		return ErrMessageTooLong
+	}
 	b = b[len(magic224):]
 	b, d.h[0] = consumeUint32(b)
 	b, d.h[1] = consumeUint32(b)
@@ -105,6 +208,130 @@ func (d *digest) UnmarshalBinary(b []byte) error {
 	return nil
 }
 
+// unmarshalCompact restores a state encoded by MarshalCompact.
+func (d *digest) unmarshalCompact(b []byte) error {
+	if err := d.checkMagic(string(b[:len(magic224Compact)]), magic224Compact, magic256Compact); err != nil {
+		return err
+	}
+	if len(b) < compactHeaderSize {
+		return errors.New("crypto/sha256: invalid hash state size")
+	}
+	_, n := consumeUint64(b[compactHeaderSize-8:])
+	if len(b) != compactHeaderSize+int(n%chunk) {
+		return errors.New("crypto/sha256: invalid hash state size")
+	}
+	if n > maxMessageLen {
+		return ErrMessageTooLong
+	}
+	b = b[len(magic256Compact):]
+	for i := range d.h {
+		b, d.h[i] = consumeUint32(b)
+	}
+	b, d.len = consumeUint64(b)
+	d.nx = copy(d.x[:], b)
+	return nil
+}
+
+// ParseState returns the algorithm, "sha224" or "sha256", and the number
+// of bytes processed recorded in the hash state b, as returned by
+// MarshalBinary or MarshalCompact. It checks the identifier, size and
+// length of b like UnmarshalBinary does, but without restoring the state
+// into a hash.
+func ParseState(b []byte) (alg string, processedLen uint64, err error) {
+	if len(b) < len(magic256) {
+		return "", 0, errors.New("crypto/sha256: invalid hash state identifier")
+	}
+	compact := false
+	switch string(b[:len(magic256)]) {
+	case magic224:
+		alg = "sha224"
+	case magic256:
+		alg = "sha256"
+	case magic224Compact:
+		alg, compact = "sha224", true
+	case magic256Compact:
+		alg, compact = "sha256", true
+	default:
+		return "", 0, errors.New("crypto/sha256: invalid hash state identifier")
+	}
+	if !compact {
+		if len(b) != MarshaledSize {
+			return "", 0, errors.New("crypto/sha256: invalid hash state size")
+		}
+		_, processedLen = consumeUint64(b[MarshaledSize-8:])
+	} else {
+		if len(b) < compactHeaderSize {
+			return "", 0, errors.New("crypto/sha256: invalid hash state size")
+		}
+		_, processedLen = consumeUint64(b[compactHeaderSize-8:])
+		if len(b) != compactHeaderSize+int(processedLen%chunk) {
+			return "", 0, errors.New("crypto/sha256: invalid hash state size")
+		}
+	}
+	if processedLen > maxMessageLen {
+		return "", 0, ErrMessageTooLong
+	}
+	return alg, processedLen, nil
+}
+
+// TotalLen returns the sum of the numbers of bytes processed recorded in
+// the hash states, as returned by MarshalBinary or MarshalCompact, for
+// example by the shards of a sharded computation. No hashing is done. An
+// error is returned if any state is malformed, as reported by ParseState,
+// or if the total overflows a uint64.
+func TotalLen(states ...[]byte) (uint64, error) {
+	var total uint64
+	for _, b := range states {
+		_, n, err := ParseState(b)
+		if err != nil {
+			return 0, err
+		}
+		if total+n < total {
+			return 0, errors.New("crypto/sha256: total length overflows")
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// MarshalText encodes the state of the hash in hexadecimal, so that it can
+// be stored in text records such as job descriptions or environment
+// variables. To keep checkpoints short it uses the encoding of
+// MarshalCompact, which UnmarshalBinary also accepts.
+func (d *digest) MarshalText() ([]byte, error) {
+	b, err := d.MarshalCompact()
+	if err != nil {
+		return nil, err
+	}
+	text := make([]byte, 2*len(b))
+	hashenc.EncodeHex(text, b)
+	return text, nil
+}
+
+// UnmarshalText restores the state encoded by MarshalText.
+func (d *digest) UnmarshalText(text []byte) error {
+	if len(text)%2 != 0 {
+		return errors.New("crypto/sha256: invalid hash state size")
+	}
+	b, ok := hashenc.DecodeHex(text)
+	if !ok {
+		return errors.New("crypto/sha256: invalid hash state encoding")
+	}
+	return d.UnmarshalBinary(b)
+}
+
+// GobEncode implements gob.GobEncoder with the same encoding as
+// MarshalBinary.
+func (d *digest) GobEncode() ([]byte, error) {
+	return d.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder; it accepts the encoding produced by
+// GobEncode or MarshalBinary.
+func (d *digest) GobDecode(b []byte) error {
+	return d.UnmarshalBinary(b)
+}
+
 func appendUint64(b []byte, x uint64) []byte {
 	var a [8]byte
 	binary.BigEndian.PutUint64(a[:], x)
@@ -131,6 +358,9 @@ func consumeUint32(b []byte) ([]byte, uint32) {
 }
 
 func (d *digest) Reset() {
+	if d.frozen {
+		return
+	}
 	if !d.is224 {
 		d.h[0] = init0
 		d.h[1] = init1
@@ -154,10 +384,30 @@ func (d *digest) Reset() {
 	d.len = 0
 }
 
+// ResetAs224 resets d to its initial state and switches it to computing
+// the SHA224 checksum, whichever of the two it computed before.
+func (d *digest) ResetAs224() {
+	if d.frozen {
+		return
+	}
+	d.is224 = true
+	d.Reset()
+}
+
+// ResetAs256 resets d to its initial state and switches it to computing
+// the SHA256 checksum, whichever of the two it computed before.
+func (d *digest) ResetAs256() {
+	if d.frozen {
+		return
+	}
+	d.is224 = false
+	d.Reset()
+}
+
 // New returns a new hash.Hash computing the SHA256 checksum. The Hash
-// also implements encoding.BinaryMarshaler and
-// encoding.BinaryUnmarshaler to marshal and unmarshal the internal
-// state of the hash.
+// also implements encoding.BinaryMarshaler, encoding.BinaryUnmarshaler,
+// encoding.TextMarshaler and encoding.TextUnmarshaler to marshal and
+// unmarshal the internal state of the hash.
 func New() hash.Hash {
 	d := new(digest)
 	d.Reset()
@@ -172,6 +422,428 @@ func New224() hash.Hash {
 	return d
 }
 
+// A Digest computes a SHA256 checksum like the hash returned by New, but
+// is a concrete type that can be embedded in other structs or kept on the
+// stack, so that it need not be allocated on the heap. The zero value is
+// ready to use. A *Digest implements hash.Hash.
+type Digest struct {
+	d     digest
+	ready bool // d has been Reset
+}
+
+// Reset resets the Digest to its initial state.
+func (d *Digest) Reset() {
+	d.d.Reset()
+	d.ready = true
+}
+
+// Write adds p to the data being hashed. The only error it returns is
+// ErrMessageTooLong, in which case nothing is hashed, once the data would
+// exceed the longest message SHA256 is defined for. A Digest cannot be
+// frozen, so the error of a write to a frozen hash does not occur.
+func (d *Digest) Write(p []byte) (nn int, err error) {
+	if !d.ready {
+		d.Reset()
+	}
+	return d.d.Write(p)
+}
+
+// WriteString adds s to the data being hashed, without converting it to a
+// slice. Like Write, it returns ErrMessageTooLong past the length limit
+// and no other error.
+func (d *Digest) WriteString(s string) (nn int, err error) {
+	if !d.ready {
+		d.Reset()
+	}
+	return d.d.WriteString(s)
+}
+
+// Sum appends the checksum of the data written so far to b and returns
+// the resulting slice. It does not change the underlying hash state.
+func (d *Digest) Sum(b []byte) []byte {
+	if !d.ready {
+		d.Reset()
+	}
+	return d.d.Sum(b)
+}
+
+// String returns the checksum of the data written so far in lowercase
+// hexadecimal, without changing the underlying hash state, so that a
+// *Digest prints as its current checksum with the %v and %s verbs. The %x
+// verb would encode that text again; print d.Sum(nil) with %x, or use
+// hex.EncodeToString(d.Sum(nil)), for the checksum in hexadecimal.
+func (d *Digest) String() string {
+	if !d.ready {
+		d.Reset()
+	}
+	return d.d.SumHex()
+}
+
+// Len returns the number of bytes written since the Digest was last reset.
+func (d *Digest) Len() uint64 { return d.d.len }
+
+// Size returns Size.
+func (d *Digest) Size() int { return Size }
+
+// BlockSize returns BlockSize.
+func (d *Digest) BlockSize() int { return BlockSize }
+
+// NewStrict returns a new hash.Hash computing the SHA256 checksum that
+// guards against reusing the hash by mistake: once Sum has been called,
+// Write panics until Reset is called. Apart from that it behaves like the
+// hash returned by New.
+func NewStrict() hash.Hash {
+	d := new(strictDigest)
+	d.Reset()
+	return d
+}
+
+// strictDigest is a digest that refuses writes between Sum and Reset.
+type strictDigest struct {
+	digest
+	summed bool // Sum has been called since the last Reset
+}
+
+func (d *strictDigest) Write(p []byte) (nn int, err error) {
+	if d.summed {
+		panic("crypto/sha256: Write after Sum without Reset")
+	}
+	return d.digest.Write(p)
+}
+
+func (d *strictDigest) WriteByte(c byte) error {
+	if d.summed {
+		panic("crypto/sha256: Write after Sum without Reset")
+	}
+	return d.digest.WriteByte(c)
+}
+
+func (d *strictDigest) WriteString(s string) (nn int, err error) {
+	if d.summed {
+		panic("crypto/sha256: Write after Sum without Reset")
+	}
+	return d.digest.WriteString(s)
+}
+
+func (d *strictDigest) Sum(in []byte) []byte {
+	d.summed = true
+	return d.digest.Sum(in)
+}
+
+func (d *strictDigest) SumTo(dst []byte) int {
+	d.summed = true
+	return d.digest.SumTo(dst)
+}
+
+func (d *strictDigest) Reset() {
+	d.summed = false
+	d.digest.Reset()
+}
+
+func (d *strictDigest) Wipe() {
+	d.wipe()
+	d.Reset()
+}
+
+func (d *strictDigest) Clone() hash.Hash {
+	d0 := *d
+	d0.frozen = false
+	return &d0
+}
+
+// NewCounting returns a new hash.Hash computing the SHA256 checksum and a
+// counter of the 64-byte blocks it compresses, for profiling. The counter
+// is increased for every block written and for the one or two padding
+// blocks of each checksum, whichever method computes it. Writes that are
+// refused are not counted. Reset does not clear the counter. The hash
+// returned by New does no counting.
+func NewCounting() (hash.Hash, *uint64) {
+	d := new(countingDigest)
+	d.Reset()
+	d.digest.blocks = &d.count
+	return d, &d.count
+}
+
+// countingDigest is a digest that counts the blocks it compresses.
+type countingDigest struct {
+	digest
+	count uint64
+}
+
+// Clone returns a copy of d with its own block counter, which starts at
+// the count of d.
+func (d *countingDigest) Clone() hash.Hash {
+	d0 := new(countingDigest)
+	*d0 = *d
+	d0.frozen = false
+	d0.digest.blocks = &d0.count
+	return d0
+}
+
+// NewWithIV returns a new hash.Hash that runs the SHA256 algorithm from
+// the initial hash value iv instead of the one in FIPS 180-4. Reset
+// restores iv. Unless iv is the standard value, the checksums are not
+// SHA256 checksums and must not be used where SHA256 or FIPS compliance
+// is expected; this is meant for experiments with the compression
+// function.
+func NewWithIV(iv [8]uint32) hash.Hash {
+	d := &ivDigest{iv: iv}
+	d.Reset()
+	return d
+}
+
+// NewTagged returns a new hash.Hash computing the tagged hash
+// SHA256(SHA256(tag) || SHA256(tag) || msg) of the data msg written to it,
+// as defined by Bitcoin's BIP 340. Hashes with different tags are domain
+// separated. The 64-byte prefix fills exactly one block, which is
+// compressed once by NewTagged; Reset restores the state after it.
+func NewTagged(tag string) hash.Hash {
+	t := Sum256([]byte(tag))
+	var prefix [BlockSize]byte
+	copy(prefix[:], t[:])
+	copy(prefix[Size:], t[:])
+	var d0 digest
+	d0.Reset()
+	block(&d0, prefix[:])
+	d := &ivDigest{iv: d0.h, ivLen: BlockSize}
+	d.Reset()
+	return d
+}
+
+// ivDigest is a digest with a custom initial hash value, which covers the
+// first ivLen bytes of the message.
+type ivDigest struct {
+	digest
+	iv    [8]uint32
+	ivLen uint64
+}
+
+func (d *ivDigest) Reset() {
+	if d.frozen {
+		return
+	}
+	d.digest.Reset()
+	d.h = d.iv
+	d.len = d.ivLen
+}
+
+// Len does not count the prefix that the initial hash value covers.
+func (d *ivDigest) Len() uint64 {
+	if d.len < d.ivLen {
+		return 0 // a foreign state was unmarshaled into d
+	}
+	return d.len - d.ivLen
+}
+
+// Wipe restores iv after wiping, like Reset.
+func (d *ivDigest) Wipe() {
+	d.wipe()
+	d.Reset()
+}
+
+func (d *ivDigest) Clone() hash.Hash {
+	d0 := *d
+	d0.frozen = false
+	return &d0
+}
+
+// ErrLimitExceeded is returned by the Write method of a hash returned by
+// NewLimited once the data written exceeds its limit.
+var ErrLimitExceeded = errors.New("crypto/sha256: input length limit exceeded")
+
+// NewLimited returns a new hash.Hash computing the SHA256 checksum of at
+// most max bytes. A Write that would take the total past max hashes only
+// the bytes up to the limit and returns their count with
+// ErrLimitExceeded; later writes hash nothing and return the same error.
+// Sum returns the checksum of the first max bytes. Reset starts over with
+// the same limit.
+func NewLimited(max uint64) hash.Hash {
+	d := &limitedDigest{max: max}
+	d.Reset()
+	return d
+}
+
+// limitedDigest is a digest that refuses to hash more than max bytes.
+type limitedDigest struct {
+	digest
+	max uint64
+}
+
+// left returns the number of bytes that can still be written.
+func (d *limitedDigest) left() uint64 {
+	if d.len >= d.max {
+		return 0
+	}
+	return d.max - d.len
+}
+
+func (d *limitedDigest) Write(p []byte) (nn int, err error) {
+	if left := d.left(); uint64(len(p)) > left {
+		nn, err = d.digest.Write(p[:left])
+		if err == nil {
+			err = ErrLimitExceeded
+		}
+		return nn, err
+	}
+	return d.digest.Write(p)
+}
+
+func (d *limitedDigest) WriteByte(c byte) error {
+	if d.left() == 0 {
+		return ErrLimitExceeded
+	}
+	return d.digest.WriteByte(c)
+}
+
+func (d *limitedDigest) WriteString(s string) (nn int, err error) {
+	if left := d.left(); uint64(len(s)) > left {
+		nn, err = d.digest.WriteString(s[:left])
+		if err == nil {
+			err = ErrLimitExceeded
+		}
+		return nn, err
+	}
+	return d.digest.WriteString(s)
+}
+
+func (d *limitedDigest) Clone() hash.Hash {
+	d0 := *d
+	d0.frozen = false
+	return &d0
+}
+
+// NewFromState returns a new hash.Hash computing the SHA256 checksum that
+// resumes from a known checksum. The chaining value is loaded from the
+// eight big-endian words of sum, and processedLen is taken as the number
+// of bytes already hashed, including the padding that produced sum.
+// Subsequent writes continue as if that message had been consumed, which
+// is the basis of a length extension attack.
+//
+// NewFromState also resumes from the words returned by the State method
+// of a SHA256 hash, after a multiple of BlockSize bytes has been written:
+// store word i in big-endian order at sum[4*i:] and pass the length as
+// processedLen. The result is always a SHA256 hash, so a SHA224 state
+// cannot be resumed this way.
+//
+// NewFromState panics if processedLen is not a multiple of BlockSize or
+// exceeds the longest message SHA256 is defined for.
+func NewFromState(sum [Size]byte, processedLen uint64) hash.Hash {
+	if processedLen%chunk != 0 {
+		panic("crypto/sha256: processed length is not a multiple of the block size")
+	}
+	if processedLen > maxMessageLen {
+		panic("crypto/sha256: processed length too long")
+	}
+	d := new(digest)
+	for i := range d.h {
+		d.h[i] = binary.BigEndian.Uint32(sum[4*i:])
+	}
+	d.len = processedLen
+	return d
+}
+
+// ExtendFromDigest performs a length extension attack. Given the SHA256
+// checksum sum of an unknown message m of origLen bytes, it returns the
+// checksum of m || glue || suffix and the glue, which is the padding that
+// SHA256 appended to m. Anyone who knows only sum and origLen can thus
+// compute a valid checksum of a longer message, which is why SHA256(key ||
+// message) is not a secure MAC; use HMAC instead. ExtendFromDigest is
+// meant for teaching and for security testing.
+//
+// ExtendFromDigest panics if the extended message exceeds the longest
+// message SHA256 is defined for.
+func ExtendFromDigest(sum [Size]byte, origLen uint64, suffix []byte) (ext [Size]byte, glue []byte) {
+	if origLen > maxMessageLen-2*chunk {
+		panic("crypto/sha256: extended message too long")
+	}
+	var buf [2 * chunk]byte
+	rest := int(origLen % chunk)
+	glue = append([]byte(nil), padBlocks(&buf, buf[:rest], origLen)[rest:]...)
+	var d digest
+	for i := range d.h {
+		d.h[i] = binary.BigEndian.Uint32(sum[4*i:])
+	}
+	d.len = origLen + uint64(len(glue))
+	if _, err := d.Write(suffix); err != nil {
+		panic("crypto/sha256: extended message too long")
+	}
+	return d.checkSum(), glue
+}
+
+// Len returns the number of bytes written to the hash since it was last
+// reset. For a hash restored with UnmarshalBinary or created by
+// NewFromState, the count includes the bytes covered by the restored
+// state.
+func (d *digest) Len() uint64 { return d.len }
+
+// State returns a copy of the chaining value and the number of bytes
+// written so far. It does not pad or finalize the hash, and writing may
+// continue afterwards. The chaining value only covers the full blocks
+// written; bytes of a partial block are still buffered. State is
+// therefore only meaningful, for example as an interior node of a hash
+// tree, after a multiple of BlockSize bytes has been written. See
+// NewFromState for resuming a SHA256 hash from the result. State is not
+// part of hash.Hash; reach it with a type assertion to
+// interface{ State() ([8]uint32, uint64) }.
+func (d *digest) State() ([8]uint32, uint64) {
+	return d.h, d.len
+}
+
+// Words returns the checksum of the data written so far as eight words,
+// each the big-endian interpretation of four bytes of the output of Sum,
+// so that word i is binary.BigEndian.Uint32(sum[4*i:]). A copy of d is
+// finalized; d itself is not changed. For SHA224 only the first seven
+// words are part of the checksum.
+func (d *digest) Words() [8]uint32 {
+	d0 := *d
+	d0.frozen = false // checkSum writes the padding
+	d0.checkSum()
+	return d0.h
+}
+
+// Clone returns an independent copy of d in the same state, so that data
+// hashed once, such as a common prefix, can be followed by different
+// suffixes. It is cheaper than a MarshalBinary and UnmarshalBinary round
+// trip. The copy is not frozen, even if d is.
+func (d *digest) Clone() hash.Hash {
+	d0 := *d
+	d0.frozen = false
+	return &d0
+}
+
+// Freeze makes d immutable, for example to guarantee that a digest whose
+// state has been recorded with MarshalBinary is not changed afterwards.
+// Once d is frozen, Write and UnmarshalBinary return an error without
+// changing the state and Reset does nothing. Sum and the marshaling
+// methods keep working. A frozen digest cannot be unfrozen.
+func (d *digest) Freeze() {
+	d.frozen = true
+}
+
+// Wipe overwrites the internal state of d, that is the chaining value,
+// the buffered input and the length, with zeros, then resets d so that it
+// can be used again. Call it after hashing secret data to shorten the
+// time the data lingers in memory. Wiping is best effort: it does not
+// guarantee that copies made by the runtime or by the caller, such as the
+// copy that Sum works on, are cleared.
+func (d *digest) Wipe() {
+	d.wipe()
+	d.Reset()
+}
+
+// wipe is Wipe without the Reset, so that the wrappers of digest can
+// reset themselves afterwards.
+func (d *digest) wipe() {
+	for i := range d.h {
+		d.h[i] = 0
+	}
+	for i := range d.x {
+		d.x[i] = 0
+	}
+	d.nx = 0
+	d.len = 0
+}
+
 func (d *digest) Size() int {
 	if !d.is224 {
 		return Size
@@ -182,6 +854,12 @@ func (d *digest) Size() int {
 func (d *digest) BlockSize() int { return BlockSize }
 
 func (d *digest) Write(p []byte) (nn int, err error) {
+	if d.frozen {
+		return 0, errors.New("crypto/sha256: write to frozen hash")
+	}
+	if d.tooLong(uint64(len(p))) {
+		return 0, ErrMessageTooLong
+	}
 	//获取写入字节数，更新d.len的值
 	nn = len(p)
 	d.len += uint64(nn)
@@ -190,7 +868,7 @@ func (d *digest) Write(p []byte) (nn int, err error) {
 		n := copy(d.x[d.nx:], p)
 		d.nx += n
 		if d.nx == chunk { //如果凑够一个分组就进行计算
-			block(d, d.x[:]) //block方法中会根据CPU参数判断执行汇编方法还是go方法
+			d.block(d.x[:]) //block方法中会根据CPU参数判断执行汇编方法还是go方法
 			d.nx = 0
 		}
 		//更改偏移量，将写入d.x中的字节去掉
@@ -222,7 +900,7 @@ func (d *digest) Write(p []byte) (nn int, err error) {
 		 * go源码中位运算的方式效率更高，但是需要的条
 		 * 是 chunk 必须是 2^n 这种形式
 		 */
-		block(d, p[:n]) //block方法中会根据CPU参数判断执行汇编方法还是go方法
+		d.block(p[:n]) //block方法中会根据CPU参数判断执行汇编方法还是go方法
 		//更改偏移量，将进行过计算的数据去掉
 		p = p[n:]
 	}
@@ -234,9 +912,63 @@ func (d *digest) Write(p []byte) (nn int, err error) {
 	return
 }
 
+// WriteString adds s to the running hash. It implements io.StringWriter
+// and behaves like Write([]byte(s)), without converting s to a slice.
+func (d *digest) WriteString(s string) (nn int, err error) {
+	if d.frozen {
+		return 0, errors.New("crypto/sha256: write to frozen hash")
+	}
+	if d.tooLong(uint64(len(s))) {
+		return 0, ErrMessageTooLong
+	}
+	d.writeString(s)
+	return len(s), nil
+}
+
+// tooLong reports whether writing n more bytes would take the message
+// past maxMessageLen. A Write that fails the check hashes nothing.
+func (d *digest) tooLong(n uint64) bool {
+	return d.len > maxMessageLen || n > maxMessageLen-d.len
+}
+
+// writeString is like Write, but takes a string. The data passes through
+// d.x, one block at a time.
+func (d *digest) writeString(s string) {
+	d.len += uint64(len(s))
+	for len(s) > 0 {
+		n := copy(d.x[d.nx:], s)
+		d.nx += n
+		s = s[n:]
+		if d.nx == chunk {
+			d.block(d.x[:])
+			d.nx = 0
+		}
+	}
+}
+
+// WriteByte adds c to the running hash. It implements io.ByteWriter and
+// behaves like Write([]byte{c}), without the cost of a slice per byte.
+func (d *digest) WriteByte(c byte) error {
+	if d.frozen {
+		return errors.New("crypto/sha256: write to frozen hash")
+	}
+	if d.tooLong(1) {
+		return ErrMessageTooLong
+	}
+	d.len++
+	d.x[d.nx] = c
+	d.nx++
+	if d.nx == chunk {
+		d.block(d.x[:])
+		d.nx = 0
+	}
+	return nil
+}
+
 func (d *digest) Sum(in []byte) []byte {
 	// Make a copy of d so that caller can keep writing and summing.
 	d0 := *d
+	d0.frozen = false // checkSum writes the padding
 	hash := d0.checkSum()
 	if d0.is224 {
 		return append(in, hash[:Size224]...)
@@ -244,8 +976,105 @@ func (d *digest) Sum(in []byte) []byte {
 	return append(in, hash[:]...)
 }
 
+// SumWithLen is like Sum but also returns the number of bytes hashed,
+// not counting the padding added by finalization.
+func (d *digest) SumWithLen(in []byte) ([]byte, uint64) {
+	return d.Sum(in), d.len
+}
+
+// Checkpoint returns the checksum of the data written so far without
+// changing d, like Sum, but as an array rather than appended to a slice.
+// For a SHA224 digest only the first Size224 bytes are used and the rest
+// are zero.
+func (d *digest) Checkpoint() [Size]byte {
+	d0 := *d
+	d0.frozen = false // checkSum writes the padding
+	return d0.checkSum()
+}
+
+// SumHex returns the checksum of the data written so far in lowercase
+// hexadecimal, without changing the underlying hash state.
+func (d *digest) SumHex() string {
+	d0 := *d
+	d0.frozen = false // checkSum writes the padding
+	sum := d0.checkSum()
+	size := Size
+	if d0.is224 {
+		size = Size224
+	}
+	var text [2 * Size]byte
+	hashenc.EncodeHex(text[:], sum[:size])
+	return string(text[:2*size])
+}
+
+// Sum224Into writes the SHA224 checksum of the data written so far into
+// the first Size224 bytes of dst and returns Size224. Like Sum, it does
+// not change the underlying hash state. It panics if d is not a SHA224
+// hash or if dst is shorter than Size224.
+func (d *digest) Sum224Into(dst []byte) int {
+	if !d.is224 {
+		panic("crypto/sha256: Sum224Into called on a SHA256 hash")
+	}
+	if len(dst) < Size224 {
+		panic("crypto/sha256: Sum224Into destination too short")
+	}
+	d0 := *d
+	d0.frozen = false // checkSum writes the padding
+	sum := d0.checkSum()
+	return copy(dst, sum[:Size224])
+}
+
+// SumReuse finalizes d in place and returns dst with the checksum copied
+// into its first Size bytes, or Size224 bytes for SHA224. Unlike Sum it
+// does not copy the hash state first, which saves time when the hash is
+// not used again: the padding is written into d, so d must be Reset
+// before it can be written to or summed again.
+func (d *digest) SumReuse(dst [Size]byte) [Size]byte {
+	if d.frozen {
+		// checkSum cannot write the padding into a frozen digest.
+		d0 := *d
+		d0.frozen = false
+		d = &d0
+	}
+	sum := d.checkSum()
+	if d.is224 {
+		copy(dst[:], sum[:Size224])
+	} else {
+		dst = sum
+	}
+	return dst
+}
+
+// SumTo finalizes d in place, writes the checksum into the first Size
+// bytes of dst, or Size224 bytes for SHA224, and returns the number of
+// bytes written. It is the slice counterpart of SumReuse: the hash state
+// is not copied first, which makes it the cheapest way to hash a message
+// once, and afterwards d must be Reset before it can be written to or
+// summed again. A frozen d is not changed. SumTo panics if dst is too
+// short.
+func (d *digest) SumTo(dst []byte) int {
+	size := Size
+	if d.is224 {
+		size = Size224
+	}
+	if len(dst) < size {
+		panic("crypto/sha256: SumTo destination too short")
+	}
+	if d.frozen {
+		// checkSum cannot write the padding into a frozen digest.
+		d0 := *d
+		d0.frozen = false
+		d = &d0
+	}
+	sum := d.checkSum()
+	return copy(dst, sum[:size])
+}
+
 func (d *digest) checkSum() [Size]byte {
 	len := d.len
+	// The padding is not part of the message, so it must not be refused
+	// by the length check in Write. len keeps the message length.
+	d.len = 0
 	// Padding. Add a 1 bit and 0 bits until 56 bytes mod 64.
 	var tmp [64]byte
 	tmp[0] = 0x80
@@ -299,3 +1128,400 @@ func Sum224(data []byte) (sum224 [Size224]byte) {
 	copy(sum224[:], sum[:Size224])
 	return
 }
+
+// Sum256String returns the SHA256 checksum of s. It is the same as
+// Sum256([]byte(s)), but does not convert s to a slice.
+func Sum256String(s string) [Size]byte {
+	var d digest
+	d.Reset()
+	d.WriteString(s)
+	return d.checkSum()
+}
+
+// Sum224String returns the SHA224 checksum of s. It is the same as
+// Sum224([]byte(s)), but does not convert s to a slice.
+func Sum224String(s string) (sum224 [Size224]byte) {
+	var d digest
+	d.is224 = true
+	d.Reset()
+	d.WriteString(s)
+	sum := d.checkSum()
+	copy(sum224[:], sum[:Size224])
+	return
+}
+
+// Sum224Into writes the SHA224 checksum of data into the first Size224
+// bytes of dst and returns Size224. It panics if dst is shorter than
+// Size224.
+func Sum224Into(dst []byte, data []byte) int {
+	if len(dst) < Size224 {
+		panic("crypto/sha256: Sum224Into destination too short")
+	}
+	var d digest
+	d.is224 = true
+	d.Reset()
+	d.Write(data)
+	sum := d.checkSum()
+	return copy(dst, sum[:Size224])
+}
+
+// Sum256Slices returns the SHA256 checksum of the concatenation of
+// slices, without joining them first. It does not allocate, so it suits
+// messages assembled from separate header and payload buffers.
+func Sum256Slices(slices ...[]byte) [Size]byte {
+	var d digest
+	d.Reset()
+	for _, s := range slices {
+		d.Write(s)
+	}
+	return d.checkSum()
+}
+
+// SumHex256 returns the SHA256 checksum of data in lowercase hexadecimal.
+func SumHex256(data []byte) string {
+	sum := Sum256(data)
+	var text [2 * Size]byte
+	hashenc.EncodeHex(text[:], sum[:])
+	return string(text[:])
+}
+
+// SumHex224 returns the SHA224 checksum of data in lowercase hexadecimal.
+func SumHex224(data []byte) string {
+	sum := Sum224(data)
+	var text [2 * Size224]byte
+	hashenc.EncodeHex(text[:], sum[:])
+	return string(text[:])
+}
+
+// block64Pad is the padding block that follows a message of exactly one
+// block: the 0x80 marker, zeros, and the bit length 512 in big-endian.
+var block64Pad = [BlockSize]byte{0: 0x80, 62: 0x02}
+
+// Sum256Block64 returns the SHA256 checksum of the 64 bytes in in, the
+// same as Sum256(in[:]). The input is compressed directly and followed by
+// the constant padding block for a 64-byte message, without the
+// bookkeeping of Write and checkSum. This suits fixed-size records such
+// as the concatenation of two checksums in a Merkle tree.
+func Sum256Block64(in *[BlockSize]byte) (sum [Size]byte) {
+	var d digest
+	d.h = [8]uint32{init0, init1, init2, init3, init4, init5, init6, init7}
+	block(&d, in[:])
+	block(&d, block64Pad[:])
+	for i, h := range d.h {
+		binary.BigEndian.PutUint32(sum[4*i:], h)
+	}
+	return sum
+}
+
+// Sum256Double returns SHA256(SHA256(data)), the double SHA256 used by
+// Bitcoin and similar protocols. The checksum is in the natural big-endian
+// byte order of SHA256; protocols that display it as a little-endian
+// number show the bytes reversed. The second hash has a fixed 32-byte
+// input, which is compressed with its padding as a single block.
+func Sum256Double(data []byte) (sum [Size]byte) {
+	var d digest
+	d.Reset()
+	d.Write(data)
+	first := d.checkSum()
+
+	// The padding of a 32-byte message: the 0x80 marker, zeros, and the
+	// bit length 256 in big-endian.
+	var b [BlockSize]byte
+	copy(b[:], first[:])
+	b[Size] = 0x80
+	b[BlockSize-2] = 0x01
+	d.Reset()
+	block(&d, b[:])
+	for i, h := range d.h {
+		binary.BigEndian.PutUint32(sum[4*i:], h)
+	}
+	return sum
+}
+
+// Sum256And224 returns both the SHA256 and the SHA224 checksum of data.
+// The two algorithms start from different initial values, so every block
+// is still compressed twice, but data is walked once and the final padded
+// blocks are built once for both.
+func Sum256And224(data []byte) (sum256 [Size]byte, sum224 [Size224]byte) {
+	var d, d224 digest
+	d.Reset()
+	d224.is224 = true
+	d224.Reset()
+
+	// Hash the full blocks in pieces small enough to still be in cache
+	// for the second compression.
+	n := len(data) &^ (chunk - 1)
+	for p := data[:n]; len(p) > 0; {
+		m := len(p)
+		if m > bufSize {
+			m = bufSize
+		}
+		block(&d, p[:m])
+		block(&d224, p[:m])
+		p = p[m:]
+	}
+	var buf [2 * chunk]byte
+	tail := padBlocks(&buf, data[n:], uint64(len(data)))
+	block(&d, tail)
+	block(&d224, tail)
+
+	for i, x := range d.h {
+		binary.BigEndian.PutUint32(sum256[4*i:], x)
+	}
+	for i, x := range d224.h[:7] {
+		binary.BigEndian.PutUint32(sum224[4*i:], x)
+	}
+	return
+}
+
+// padBlocks builds the final one or two blocks of a message of length
+// bytes in buf and returns them, as hashenc.Pad does with the big-endian
+// length field of SHA256.
+func padBlocks(buf *[2 * chunk]byte, rest []byte, length uint64) []byte {
+	return hashenc.Pad(buf, rest, length, binary.BigEndian)
+}
+
+// DeriveNonce returns a deterministic nonce of size bytes derived from key
+// and counter. The nonce is the first size bytes of
+// SHA256(key || counter), where counter is encoded as 8 big-endian bytes.
+//
+// The same key and counter always produce the same nonce, so DeriveNonce
+// must not be used where a random nonce is required. It is an error to
+// request more than Size bytes or a negative size.
+func DeriveNonce(key []byte, counter uint64, size int) ([]byte, error) {
+	if size < 0 || size > Size {
+		return nil, errors.New("crypto/sha256: invalid nonce size")
+	}
+	var d digest
+	d.Reset()
+	d.Write(key)
+	var c [8]byte
+	binary.BigEndian.PutUint64(c[:], counter)
+	d.Write(c[:])
+	sum := d.checkSum()
+	nonce := make([]byte, size)
+	copy(nonce, sum[:])
+	return nonce, nil
+}
+
+// HasAsm reports whether the SHA-256 block function runs an assembly
+// implementation on the current CPU. It reports the same decision the
+// block dispatcher makes, not a fresh detection of CPU features.
+func HasAsm() bool {
+	return useAsm
+}
+
+// implementation is the name of the block implementation in use.
+var implementation = implementations()[0]
+
+// Setting GODEBUG=sha256asm=0 in the environment makes the package start
+// out with the generic implementation, so that results can be compared
+// against it without changing the program. GODEBUG=sha256impl=name starts
+// it out with the named implementation instead, as if by SetImplementation;
+// an unavailable name is ignored. sha256asm=0 takes precedence.
+func init() {
+	env := os.Getenv("GODEBUG")
+	if impl := godebug(env, "sha256impl"); impl != "" {
+		SetImplementation(impl)
+	}
+	if godebug(env, "sha256asm") == "0" {
+		setImplementation("generic")
+		implementation = "generic"
+	}
+}
+
+// fastestImplementation times each of impls hashing the same input and
+// returns the name of the fastest. Each is timed a few times and judged
+// by its best run, to discount preemption and cold caches. avx512 is not
+// timed: it hashes single messages with the avx2 code, so it is kept only
+// if it is listed first and avx2 wins.
+func fastestImplementation(impls []string) string {
+	var buf [64 * BlockSize]byte
+	best, bestTime := impls[0], time.Duration(-1)
+	for _, impl := range impls {
+		if impl == "avx512" {
+			continue
+		}
+		setImplementation(impl)
+		t := time.Duration(-1)
+		for i := 0; i < 3; i++ {
+			var d digest
+			start := time.Now()
+			block(&d, buf[:])
+			if elapsed := time.Since(start); t < 0 || elapsed < t {
+				t = elapsed
+			}
+		}
+		if bestTime < 0 || t < bestTime {
+			best, bestTime = impl, t
+		}
+	}
+	if best == "avx2" && impls[0] == "avx512" {
+		best = "avx512"
+	}
+	setImplementation(implementation)
+	return best
+}
+
+// godebug returns the value of the setting key in env, which has the
+// form of the GODEBUG environment variable. As in the runtime, the last
+// setting of key wins.
+func godebug(env, key string) string {
+	value := ""
+	for _, kv := range strings.Split(env, ",") {
+		if i := strings.IndexByte(kv, '='); i >= 0 && kv[:i] == key {
+			value = kv[i+1:]
+		}
+	}
+	return value
+}
+
+// Implementation returns the name of the implementation of the SHA-256
+// block function in use, such as "shani", "avx2" or "generic". It is
+// "generic" at startup if the GODEBUG environment variable contains
+// sha256asm=0, and the one chosen by a sha256impl setting if it has one.
+func Implementation() string {
+	return implementation
+}
+
+// SetImplementation selects the implementation of the SHA-256 block
+// function used by all hashes in the process. On amd64 impl can be
+// "shani", "avx512", "avx2", "amd64" (the scalar assembly) or "generic",
+// where avx512 is avx2 with wider lanes for Sum256Batch; on other
+// architectures it can be the name of the architecture, if it has an
+// assembly implementation, or "generic". "auto" restores the fastest
+// implementation the CPU supports, judged by its feature flags. "fastest"
+// instead times each implementation on a short input and keeps the one
+// that ran fastest, which helps on CPUs whose flags overstate what they
+// do well; it takes on the order of a millisecond. An error is returned,
+// and the implementation is left unchanged, if impl is not available on
+// the current CPU.
+//
+// SetImplementation is meant for testing and benchmarking. It must not be
+// called concurrently with any use of the package.
+func SetImplementation(impl string) error {
+	impls := implementations()
+	switch impl {
+	case "auto":
+		impl = impls[0]
+	case "fastest":
+		impl = fastestImplementation(impls)
+	}
+	for _, name := range impls {
+		if name == impl {
+			setImplementation(impl)
+			implementation = impl
+			return nil
+		}
+	}
+	return errors.New("crypto/sha256: implementation " + strconv.Quote(impl) + " is not available")
+}
+
+// Compress applies the SHA256 compression function to one block, updating
+// the chaining value in state in place. It uses the same implementation
+// as the hashes returned by New, including any assembly.
+//
+// Compress is a low-level primitive for building other constructions. It
+// does not pad or count the message, so on its own it does not compute a
+// SHA256 checksum.
+func Compress(state *[8]uint32, b *[BlockSize]byte) {
+	d := digest{h: *state}
+	block(&d, b[:])
+	*state = d.h
+}
+
+// Block applies the SHA256 compression function to each 64-byte block of
+// p in turn, updating the chaining value in state in place. It is the
+// multi-block form of Compress and, like it, does no padding or length
+// counting. Block panics if len(p) is not a multiple of BlockSize.
+func Block(state *[8]uint32, p []byte) {
+	if len(p)%BlockSize != 0 {
+		panic("crypto/sha256: Block input is not a multiple of BlockSize")
+	}
+	if len(p) == 0 {
+		return
+	}
+	d := digest{h: *state}
+	block(&d, p)
+	*state = d.h
+}
+
+// Equal reports whether sum1 and sum2 are equal SHA256 checksums.
+// The comparison runs in constant time, so it is safe to use for
+// verifying MACs and other secret-dependent values.
+func Equal(sum1, sum2 [Size]byte) bool {
+	return subtle.ConstantTimeCompare(sum1[:], sum2[:]) == 1
+}
+
+// EqualBytes reports whether mac, for example a checksum received over the
+// network, equals the SHA256 checksum sum. It returns false if mac is not
+// Size bytes long. The comparison runs in constant time; only the length
+// of mac may leak.
+func EqualBytes(mac []byte, sum [Size]byte) bool {
+	return subtle.ConstantTimeCompare(mac, sum[:]) == 1
+}
+
+// Equal224 reports whether sum1 and sum2 are equal SHA224 checksums.
+// The comparison runs in constant time.
+func Equal224(sum1, sum2 [Size224]byte) bool {
+	return subtle.ConstantTimeCompare(sum1[:], sum2[:]) == 1
+}
+
+// Commit returns a hash commitment to value, blinded by nonce:
+//
+//	SHA256(len(nonce) || nonce || value)
+//
+// where len(nonce) is encoded as 8 big-endian bytes. Prefixing the length
+// of the nonce makes the split between nonce and value unambiguous, so a
+// commitment cannot be opened to a different (nonce, value) pair with the
+// same concatenation. The nonce should be at least 32 random bytes and
+// must never be reused; it is revealed together with value to open the
+// commitment.
+func Commit(value, nonce []byte) [Size]byte {
+	var d digest
+	d.Reset()
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(len(nonce)))
+	d.Write(n[:])
+	d.Write(nonce)
+	d.Write(value)
+	return d.checkSum()
+}
+
+// Chain returns SHA256(prev || data), the next link of a hash chain such
+// as an append-only log, in which each entry's checksum covers the
+// checksum of the entry before it. It is equivalent to hashing the
+// concatenation, but does not allocate or join prev and data.
+func Chain(prev [Size]byte, data []byte) [Size]byte {
+	var d digest
+	d.Reset()
+	d.nx = copy(d.x[:], prev[:])
+	d.len = Size
+	d.Write(data)
+	return d.checkSum()
+}
+
+// VerifyCommitment reports whether value and nonce open commitment, that
+// is, whether commitment == Commit(value, nonce). The comparison runs in
+// constant time.
+func VerifyCommitment(commitment [Size]byte, value, nonce []byte) bool {
+	return Equal(commitment, Commit(value, nonce))
+}
+
+// MaxCodeLen is the longest code Sum256Code will produce. Each base-45
+// digit carries about 5.49 bits, and 46 digits is the most that a 256-bit
+// checksum can fill with uniformly distributed values.
+const MaxCodeLen = 46
+
+// Sum256Code returns a short code derived from the SHA256 checksum of data
+// that can be stored in a QR code using alphanumeric mode. The checksum is
+// read as a big-endian integer and written in base 45 using the digits
+// "0-9", "A-Z" and " $%*+-./:"; the code is the last length digits.
+// Sum256Code panics if length is not between 1 and MaxCodeLen.
+func Sum256Code(data []byte, length int) string {
+	if length < 1 || length > MaxCodeLen {
+		panic("crypto/sha256: invalid code length")
+	}
+	sum := Sum256(data)
+	return hashenc.EncodeCode(sum[:], length)
+}
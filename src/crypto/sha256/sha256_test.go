@@ -10,9 +10,17 @@ import (
 	"bytes"
 	"crypto/rand"
 	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"hash"
 	"io"
+	"os"
+	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -196,6 +204,47 @@ func TestMarshalTypeMismatch(t *testing.T) {
 	}
 }
 
+func TestStateMismatchError(t *testing.T) {
+	type compactMarshaler interface {
+		MarshalCompact() ([]byte, error)
+	}
+	for _, tt := range []struct {
+		from, to func() hash.Hash
+		alg      string
+	}{
+		{New, New224, "sha256"},
+		{New224, New, "sha224"},
+	} {
+		h := tt.from()
+		h.Write([]byte("abc"))
+		full, _ := h.(encoding.BinaryMarshaler).MarshalBinary()
+		compact, _ := h.(compactMarshaler).MarshalCompact()
+		js, _ := h.(json.Marshaler).MarshalJSON()
+		for _, unmarshal := range []func(hash.Hash) error{
+			func(h hash.Hash) error { return h.(encoding.BinaryUnmarshaler).UnmarshalBinary(full) },
+			func(h hash.Hash) error { return h.(encoding.BinaryUnmarshaler).UnmarshalBinary(compact) },
+			func(h hash.Hash) error { return h.(json.Unmarshaler).UnmarshalJSON(js) },
+		} {
+			err := unmarshal(tt.to())
+			var mismatch *StateMismatchError
+			if !errors.As(err, &mismatch) || mismatch.Alg != tt.alg {
+				t.Errorf("restoring a %s state into the other hash: error %v, want a *StateMismatchError for %s", tt.alg, err, tt.alg)
+				continue
+			}
+			// The algorithm in the error picks the right hash.
+			h2 := New()
+			if mismatch.Alg == "sha224" {
+				h2 = New224()
+			}
+			if err := unmarshal(h2); err != nil {
+				t.Errorf("restoring a %s state into a %s hash: %v", tt.alg, tt.alg, err)
+			} else if got, want := h2.Sum(nil), h.Sum(nil); !bytes.Equal(got, want) {
+				t.Errorf("restored %s state: Sum = %x, want %x", tt.alg, got, want)
+			}
+		}
+	}
+}
+
 func TestSize(t *testing.T) {
 	c := New()
 	if got := c.Size(); got != Size {
@@ -289,27 +338,1972 @@ func TestLargeHashes(t *testing.T) {
 	}
 }
 
-var bench = New()
-var buf = make([]byte, 8192)
+var deriveNonceTests = []struct {
+	key     string
+	counter uint64
+	size    int
+	out     string
+}{
+	{"", 0, 32, "af5570f5a1810b7af78caf4bc70a660f0df51e42baf91d4de5b2328de0e83dfc"},
+	{"key", 1, 12, "78694467bf9cd7c06d8b2c53"},
+	{"key", 1<<64 - 1, 16, "623c754be8e17344a1e2d9f4ed7d2caa"},
+	{"secret key", 42, 24, "f7719aff4397b18204638528d02208e879a918f1a1e90a2c"},
+	{"key", 7, 0, ""},
+}
 
-func benchmarkSize(b *testing.B, size int) {
-	b.SetBytes(int64(size))
-	sum := make([]byte, bench.Size())
-	for i := 0; i < b.N; i++ {
-		bench.Reset()
-		bench.Write(buf[:size])
-		bench.Sum(sum[:0])
+func TestDeriveNonce(t *testing.T) {
+	for _, tt := range deriveNonceTests {
+		nonce, err := DeriveNonce([]byte(tt.key), tt.counter, tt.size)
+		if err != nil {
+			t.Errorf("DeriveNonce(%q, %d, %d) error: %v", tt.key, tt.counter, tt.size, err)
+			continue
+		}
+		if s := fmt.Sprintf("%x", nonce); s != tt.out {
+			t.Errorf("DeriveNonce(%q, %d, %d) = %s want %s", tt.key, tt.counter, tt.size, s, tt.out)
+		}
+	}
+	for _, size := range []int{-1, Size + 1} {
+		if _, err := DeriveNonce([]byte("key"), 0, size); err == nil {
+			t.Errorf("DeriveNonce with size %d: no error when one was expected", size)
+		}
 	}
 }
 
-func BenchmarkHash8Bytes(b *testing.B) {
-	benchmarkSize(b, 8)
+func TestHasAsm(t *testing.T) {
+	if godebug(os.Getenv("GODEBUG"), "sha256asm") == "0" {
+		if HasAsm() {
+			t.Error("HasAsm() = true with GODEBUG=sha256asm=0, want false")
+		}
+		return
+	}
+	if godebug(os.Getenv("GODEBUG"), "sha256impl") != "" {
+		if want := Implementation() != "generic"; HasAsm() != want {
+			t.Errorf("HasAsm() = %v with implementation %q, want %v", HasAsm(), Implementation(), want)
+		}
+		return
+	}
+	switch runtime.GOARCH {
+	case "386", "amd64", "ppc64le":
+		if !HasAsm() {
+			t.Errorf("HasAsm() = false on %s, want true", runtime.GOARCH)
+		}
+	case "arm64", "s390x":
+		// Depends on CPU features.
+	default:
+		if HasAsm() {
+			t.Errorf("HasAsm() = true on %s, want false", runtime.GOARCH)
+		}
+	}
 }
 
-func BenchmarkHash1K(b *testing.B) {
-	benchmarkSize(b, 1024)
+func TestGodebug(t *testing.T) {
+	tests := []struct {
+		env, want string
+	}{
+		{"", ""},
+		{"sha256asm=0", "0"},
+		{"sha256asm=1", "1"},
+		{"madvdontneed=1,sha256asm=0", "0"},
+		{"sha256asm=0,sha256asm=1", "1"},
+		{"xsha256asm=0", ""},
+		{"sha256asmx=0", ""},
+		{"sha256asm", ""},
+		{"sha256asm=", ""},
+	}
+	for _, tt := range tests {
+		if got := godebug(tt.env, "sha256asm"); got != tt.want {
+			t.Errorf("godebug(%q, \"sha256asm\") = %q, want %q", tt.env, got, tt.want)
+		}
+	}
 }
 
-func BenchmarkHash8K(b *testing.B) {
-	benchmarkSize(b, 8192)
+func TestSetImplementation(t *testing.T) {
+	defer SetImplementation("auto")
+	impls := implementations()
+	initial := impls[0]
+	if godebug(os.Getenv("GODEBUG"), "sha256asm") == "0" {
+		initial = "generic"
+	}
+	if godebug(os.Getenv("GODEBUG"), "sha256impl") == "" {
+		if got := Implementation(); got != initial {
+			t.Errorf("Implementation() = %q, want %q", got, initial)
+		}
+	}
+	data := make([]byte, 10*chunk+7)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	var want digest
+	want.Reset()
+	blockGeneric(&want, data)
+	for _, impl := range impls {
+		if err := SetImplementation(impl); err != nil {
+			t.Fatalf("SetImplementation(%q): %v", impl, err)
+		}
+		if got := Implementation(); got != impl {
+			t.Errorf("Implementation() = %q after SetImplementation(%q)", got, impl)
+		}
+		for _, g := range golden {
+			if s := fmt.Sprintf("%x", Sum256([]byte(g.in))); s != g.out {
+				t.Errorf("%s: Sum256(%q) = %s, want %s", impl, g.in, s, g.out)
+			}
+		}
+		for _, g := range golden224 {
+			if s := fmt.Sprintf("%x", Sum224([]byte(g.in))); s != g.out {
+				t.Errorf("%s: Sum224(%q) = %s, want %s", impl, g.in, s, g.out)
+			}
+		}
+		var got digest
+		got.Reset()
+		block(&got, data)
+		if got.h != want.h {
+			t.Errorf("%s: block and blockGeneric resulted in different states", impl)
+		}
+	}
+
+	if err := SetImplementation("auto"); err != nil {
+		t.Fatalf("SetImplementation(\"auto\"): %v", err)
+	}
+	if got := Implementation(); got != impls[0] {
+		t.Errorf("Implementation() = %q after SetImplementation(\"auto\"), want %q", got, impls[0])
+	}
+	for _, impl := range []string{"", "AVX2", "neon", "sha512"} {
+		if err := SetImplementation(impl); err == nil {
+			t.Errorf("SetImplementation(%q): no error when one was expected", impl)
+		}
+		if got := Implementation(); got != impls[0] {
+			t.Errorf("Implementation() = %q after failed SetImplementation(%q), want %q", got, impl, impls[0])
+		}
+	}
+}
+
+func TestSetImplementationFastest(t *testing.T) {
+	defer SetImplementation("auto")
+	impls := implementations()
+	if err := SetImplementation("generic"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetImplementation("fastest"); err != nil {
+		t.Fatalf("SetImplementation(\"fastest\"): %v", err)
+	}
+	got := Implementation()
+	found := false
+	for _, impl := range impls {
+		found = found || impl == got
+	}
+	if !found {
+		t.Fatalf("Implementation() = %q after SetImplementation(\"fastest\"), want one of %q", got, impls)
+	}
+	for _, g := range golden {
+		if s := fmt.Sprintf("%x", Sum256([]byte(g.in))); s != g.out {
+			t.Errorf("%s: Sum256(%q) = %s, want %s", got, g.in, s, g.out)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a := Sum256([]byte("abc"))
+	b := a
+	if !Equal(a, b) {
+		t.Errorf("Equal(%x, %x) = false, want true", a, b)
+	}
+	for _, i := range []int{0, Size - 1} {
+		c := a
+		c[i] ^= 1
+		if Equal(a, c) {
+			t.Errorf("Equal(%x, %x) = true, want false", a, c)
+		}
+	}
+
+	if !EqualBytes(b[:], a) {
+		t.Errorf("EqualBytes(%x, %x) = false, want true", b, a)
+	}
+	for _, mac := range [][]byte{nil, b[:Size-1], append(b[:], 0), append([]byte{1}, b[1:]...)} {
+		if EqualBytes(mac, a) {
+			t.Errorf("EqualBytes(%x, %x) = true, want false", mac, a)
+		}
+	}
+
+	a224 := Sum224([]byte("abc"))
+	b224 := a224
+	if !Equal224(a224, b224) {
+		t.Errorf("Equal224(%x, %x) = false, want true", a224, b224)
+	}
+	for _, i := range []int{0, Size224 - 1} {
+		c := a224
+		c[i] ^= 1
+		if Equal224(a224, c) {
+			t.Errorf("Equal224(%x, %x) = true, want false", a224, c)
+		}
+	}
+}
+
+func TestSum256Code(t *testing.T) {
+	tests := []struct {
+		in     string
+		length int
+		out    string
+	}{
+		{"", MaxCodeLen, "A7J37HFS*W %NU%FP65H:M*E3+$OHJ0 D+M J8MBPE0-MY"},
+		{"abc", 1, "A"},
+		{"hello world\n", 8, "-QAQ1/OG"},
+	}
+	for _, tt := range tests {
+		if code := Sum256Code([]byte(tt.in), tt.length); code != tt.out {
+			t.Errorf("Sum256Code(%q, %d) = %q, want %q", tt.in, tt.length, code, tt.out)
+		}
+	}
+	for _, length := range []int{0, MaxCodeLen + 1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Sum256Code with length %d did not panic", length)
+				}
+			}()
+			Sum256Code(nil, length)
+		}()
+	}
+}
+
+// padding returns the SHA256 padding appended to a message of n bytes.
+func padding(n uint64) []byte {
+	pad := []byte{0x80}
+	for (n+uint64(len(pad)))%BlockSize != 56 {
+		pad = append(pad, 0)
+	}
+	var l [8]byte
+	binary.BigEndian.PutUint64(l[:], n<<3)
+	return append(pad, l[:]...)
+}
+
+func TestNewFromStateLengthExtension(t *testing.T) {
+	secret := []byte("a secret the attacker does not know")
+	msg := []byte("user=alice&role=user")
+	suffix := []byte("&role=admin")
+
+	// The attacker knows the MAC and the length of secret || msg.
+	mac := Sum256(append(append([]byte{}, secret...), msg...))
+	origLen := uint64(len(secret) + len(msg))
+	pad := padding(origLen)
+
+	h := NewFromState(mac, origLen+uint64(len(pad)))
+	h.Write(suffix)
+	forged := h.Sum(nil)
+
+	// The server computes the MAC of secret || msg || pad || suffix.
+	var full []byte
+	full = append(full, secret...)
+	full = append(full, msg...)
+	full = append(full, pad...)
+	full = append(full, suffix...)
+	want := Sum256(full)
+	if !bytes.Equal(forged, want[:]) {
+		t.Errorf("forged = %x, want %x", forged, want)
+	}
+}
+
+func TestExtendFromDigest(t *testing.T) {
+	secret := []byte("a secret the attacker does not know")
+	suffix := []byte("&role=admin")
+	for _, msg := range []string{"", "user=alice&role=user", strings.Repeat("x", 20), strings.Repeat("x", 21), strings.Repeat("x", 29), strings.Repeat("y", 100)} {
+		m := append(append([]byte{}, secret...), msg...)
+		ext, glue := ExtendFromDigest(Sum256(m), uint64(len(m)), suffix)
+		if want := padding(uint64(len(m))); !bytes.Equal(glue, want) {
+			t.Errorf("ExtendFromDigest glue for %d bytes = %x, want %x", len(m), glue, want)
+		}
+		full := append(append(m, glue...), suffix...)
+		if want := Sum256(full); ext != want {
+			t.Errorf("ExtendFromDigest after %d bytes = %x, want %x", len(m), ext, want)
+		}
+	}
+}
+
+func TestNewFromStatePanicsOnUnalignedLength(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewFromState with unaligned length did not panic")
+		}
+	}()
+	NewFromState(Sum256(nil), 63)
+}
+
+func TestMarshalText(t *testing.T) {
+	tests := []struct {
+		name    string
+		newHash func() hash.Hash
+		gold    []sha256Test
+	}{
+		{"256", New, golden},
+		{"224", New224, golden224},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, g := range tt.gold {
+				h := tt.newHash()
+				io.WriteString(h, g.in[:len(g.in)/2])
+
+				type config struct {
+					Name  string
+					State *digest
+				}
+				data, err := json.Marshal(config{"x", h.(*digest)})
+				if err != nil {
+					t.Errorf("could not marshal: %v", err)
+					continue
+				}
+				c := config{State: tt.newHash().(*digest)}
+				if err := json.Unmarshal(data, &c); err != nil {
+					t.Errorf("could not unmarshal %s: %v", data, err)
+					continue
+				}
+				h2 := c.State
+
+				io.WriteString(h, g.in[len(g.in)/2:])
+				io.WriteString(h2, g.in[len(g.in)/2:])
+
+				if actual, actual2 := h.Sum(nil), h2.Sum(nil); !bytes.Equal(actual, actual2) {
+					t.Errorf("sha%s(%q) = 0x%x != text marshaled 0x%x", tt.name, g.in, actual, actual2)
+				}
+			}
+		})
+	}
+}
+
+func TestUnmarshalTextErrors(t *testing.T) {
+	state, err := New().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		t.Fatalf("could not marshal: %v", err)
+	}
+	if string(state[:8]) != "73686183" || len(state) != 2*compactHeaderSize {
+		t.Errorf("MarshalText() = %s, want a compact state with hex prefix 73686183", state)
+	}
+	tests := []struct {
+		text string
+		err  string
+	}{
+		{string(state[:len(state)-1]), "crypto/sha256: invalid hash state size"},
+		{string(state[:len(state)-2]), "crypto/sha256: invalid hash state size"},
+		{"73686104" + string(state[8:]), "crypto/sha256: invalid hash state identifier"},
+		{"73686182" + string(state[8:]), "crypto/sha256: cannot restore sha224 hash state into sha256 hash"},
+		{"7368610z" + string(state[8:]), "crypto/sha256: invalid hash state encoding"},
+	}
+	for _, tt := range tests {
+		err := New().(encoding.TextUnmarshaler).UnmarshalText([]byte(tt.text))
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("UnmarshalText(%.16q...) = %v, want %q", tt.text, err, tt.err)
+		}
+	}
+}
+
+var commitTests = []struct {
+	value, nonce string
+	out          string
+}{
+	{"", "", "af5570f5a1810b7af78caf4bc70a660f0df51e42baf91d4de5b2328de0e83dfc"},
+	{"hello", "nonce", "60cfdb4d8619a1d6d1f1879c1162541674e81f8f2583096c39fa550906a9c71f"},
+	{"nonce", "", "0c2dca11372d2a0711f13c0d5bbb767c7ffd4738b6351e795e1f400015a3280b"},
+	{"value", "0123456789abcdef0123456789abcdef", "62578a6c5dd4fcc3a20e94611313b514842355f3df87fcb2e128160b5a964bb5"},
+}
+
+func TestCommit(t *testing.T) {
+	for _, tt := range commitTests {
+		c := Commit([]byte(tt.value), []byte(tt.nonce))
+		if s := fmt.Sprintf("%x", c); s != tt.out {
+			t.Errorf("Commit(%q, %q) = %s want %s", tt.value, tt.nonce, s, tt.out)
+		}
+		if !VerifyCommitment(c, []byte(tt.value), []byte(tt.nonce)) {
+			t.Errorf("VerifyCommitment(Commit(%q, %q)) = false", tt.value, tt.nonce)
+		}
+	}
+
+	// Moving bytes between the nonce and the value must not open the
+	// commitment.
+	c := Commit([]byte("value"), []byte("nonce"))
+	if VerifyCommitment(c, []byte("evalue"), []byte("nonc")) {
+		t.Error("VerifyCommitment accepted a shifted nonce/value split")
+	}
+	if VerifyCommitment(c, []byte("value!"), []byte("nonce")) {
+		t.Error("VerifyCommitment accepted a different value")
+	}
+}
+
+func TestSum256And224(t *testing.T) {
+	data := make([]byte, 2*bufSize+100)
+	rand.Read(data)
+	for _, n := range []int{0, 1, 55, 56, 63, 64, 65, 119, 120, 1000, bufSize, len(data)} {
+		sum256, sum224 := Sum256And224(data[:n])
+		if want := Sum256(data[:n]); sum256 != want {
+			t.Errorf("Sum256And224(%d bytes) SHA256 = %x, want %x", n, sum256, want)
+		}
+		if want := Sum224(data[:n]); sum224 != want {
+			t.Errorf("Sum256And224(%d bytes) SHA224 = %x, want %x", n, sum224, want)
+		}
+	}
+}
+
+func TestNewStrict(t *testing.T) {
+	h := NewStrict()
+	io.WriteString(h, "abc")
+	sum := h.Sum(nil)
+	if !bytes.Equal(sum, h.Sum(nil)) {
+		t.Error("second Sum differs from the first")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Write after Sum did not panic")
+			}
+		}()
+		io.WriteString(h, "def")
+	}()
+
+	h.Reset()
+	io.WriteString(h, "abc")
+	if got := h.Sum(nil); !bytes.Equal(got, sum) {
+		t.Errorf("Sum after Reset = %x, want %x", got, sum)
+	}
+
+	// New keeps hashing after Sum.
+	h = New()
+	io.WriteString(h, "abc")
+	h.Sum(nil)
+	io.WriteString(h, "def")
+	want := Sum256([]byte("abcdef"))
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("New: Sum after Write after Sum = %x, want %x", got, want)
+	}
+}
+
+func TestState(t *testing.T) {
+	data := make([]byte, 2*BlockSize)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	h := New()
+	h.Write(data)
+	state, n := h.(interface {
+		State() ([8]uint32, uint64)
+	}).State()
+
+	var d digest
+	d.Reset()
+	block(&d, data)
+	if state != d.h || n != uint64(len(data)) {
+		t.Errorf("State() = %x, %d, want %x, %d", state, n, d.h, len(data))
+	}
+
+	// State must not finalize the hash.
+	h.Write([]byte("abc"))
+	want := Sum256(append(data, "abc"...))
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("Sum after State = %x, want %x", got, want)
+	}
+}
+
+func TestStateNewFromState(t *testing.T) {
+	data := make([]byte, 3*BlockSize+10)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	want := Sum256(data)
+	h := New()
+	h.Write(data[:2*BlockSize])
+	words, n := h.(interface {
+		State() ([8]uint32, uint64)
+	}).State()
+	var sum [Size]byte
+	for i, w := range words {
+		binary.BigEndian.PutUint32(sum[4*i:], w)
+	}
+	r := NewFromState(sum, n)
+	r.Write(data[n:])
+	if got := r.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("NewFromState from State = %x, want %x", got, want)
+	}
+}
+
+func TestGob(t *testing.T) {
+	for _, newHash := range []func() hash.Hash{New, New224} {
+		h := newHash()
+		io.WriteString(h, "a mid-stream message spanning more than one block, "+
+			"so that both the chaining value and the buffer matter")
+
+		var b bytes.Buffer
+		if err := gob.NewEncoder(&b).Encode(h); err != nil {
+			t.Fatalf("could not gob encode: %v", err)
+		}
+		h2 := newHash()
+		if err := gob.NewDecoder(&b).Decode(h2); err != nil {
+			t.Fatalf("could not gob decode: %v", err)
+		}
+
+		io.WriteString(h, "and the rest")
+		io.WriteString(h2, "and the rest")
+		if actual, actual2 := h.Sum(nil), h2.Sum(nil); !bytes.Equal(actual, actual2) {
+			t.Errorf("sum = 0x%x != gob decoded 0x%x", actual, actual2)
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	data := make([]byte, 200)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	for _, n := range []int{0, 1, 55, 56, 64, 119, 200} {
+		// Pad the message by hand: 0x80, zeros, and the bit length.
+		msg := append([]byte(nil), data[:n]...)
+		msg = append(msg, 0x80)
+		for len(msg)%BlockSize != 56 {
+			msg = append(msg, 0)
+		}
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(n)<<3)
+		msg = append(msg, length[:]...)
+
+		state := [8]uint32{init0, init1, init2, init3, init4, init5, init6, init7}
+		for p := msg; len(p) > 0; p = p[BlockSize:] {
+			var b [BlockSize]byte
+			copy(b[:], p)
+			Compress(&state, &b)
+		}
+		var sum [Size]byte
+		for i, x := range state {
+			binary.BigEndian.PutUint32(sum[4*i:], x)
+		}
+		if want := Sum256(data[:n]); sum != want {
+			t.Errorf("Compress over %d padded bytes = %x, want %x", n, sum, want)
+		}
+	}
+}
+
+func TestBlock(t *testing.T) {
+	data := make([]byte, 5*BlockSize)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	for n := 0; n <= len(data); n += BlockSize {
+		want := [8]uint32{init0, init1, init2, init3, init4, init5, init6, init7}
+		for p := data[:n]; len(p) > 0; p = p[BlockSize:] {
+			var b [BlockSize]byte
+			copy(b[:], p)
+			Compress(&want, &b)
+		}
+		got := [8]uint32{init0, init1, init2, init3, init4, init5, init6, init7}
+		Block(&got, data[:n])
+		if got != want {
+			t.Errorf("Block over %d bytes = %x, want %x", n, got, want)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Block did not panic on a partial block")
+		}
+	}()
+	var state [8]uint32
+	Block(&state, data[:BlockSize+1])
+}
+
+func TestFreeze(t *testing.T) {
+	h := New()
+	io.WriteString(h, "abc")
+	want := h.Sum(nil)
+	state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		t.Fatalf("could not marshal: %v", err)
+	}
+
+	h.(interface{ Freeze() }).Freeze()
+	if n, err := io.WriteString(h, "def"); n != 0 || err == nil {
+		t.Errorf("Write after Freeze = %d, %v, want 0 and an error", n, err)
+	}
+	h.Reset()
+	if err := h.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err == nil {
+		t.Error("UnmarshalBinary after Freeze: no error when one was expected")
+	}
+	if got := h.Sum(nil); !bytes.Equal(got, want) {
+		t.Errorf("Sum after Freeze = %x, want %x", got, want)
+	}
+	if got := h.Sum(nil); !bytes.Equal(got, want) {
+		t.Errorf("second Sum after Freeze = %x, want %x", got, want)
+	}
+	state2, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		t.Fatalf("could not marshal frozen hash: %v", err)
+	}
+	if !bytes.Equal(state2, state) {
+		t.Errorf("MarshalBinary after Freeze = %q, want %q", state2, state)
+	}
+}
+
+func TestSumString(t *testing.T) {
+	for _, g := range golden {
+		if got, want := Sum256String(g.in), Sum256([]byte(g.in)); got != want {
+			t.Errorf("Sum256String(%q) = %x, want %x", g.in, got, want)
+		}
+	}
+	for _, g := range golden224 {
+		if got, want := Sum224String(g.in), Sum224([]byte(g.in)); got != want {
+			t.Errorf("Sum224String(%q) = %x, want %x", g.in, got, want)
+		}
+	}
+	s := strings.Repeat("0123456789", 30)
+	if n := testing.AllocsPerRun(10, func() { Sum256String(s) }); n > 0 {
+		t.Errorf("Sum256String allocs = %v, want 0", n)
+	}
+	if n := testing.AllocsPerRun(10, func() { Sum224String(s) }); n > 0 {
+		t.Errorf("Sum224String allocs = %v, want 0", n)
+	}
+}
+
+func TestSum256Slices(t *testing.T) {
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	tests := [][][]byte{
+		nil,
+		{},
+		{data[:0]},
+		{data[:3]},
+		{data[:100]},
+		{data[:10], data[10:70], data[70:71], data[71:71], data[71:200], data[200:]},
+	}
+	for _, slices := range tests {
+		var joined []byte
+		for _, s := range slices {
+			joined = append(joined, s...)
+		}
+		if got, want := Sum256Slices(slices...), Sum256(joined); got != want {
+			t.Errorf("Sum256Slices(%d slices, %d bytes) = %x, want %x", len(slices), len(joined), got, want)
+		}
+	}
+	if got, want := Sum256Slices(), Sum256(nil); got != want {
+		t.Errorf("Sum256Slices() = %x, want %x", got, want)
+	}
+
+	header, payload := data[:12], data[12:]
+	if n := testing.AllocsPerRun(10, func() { Sum256Slices(header, payload) }); n > 0 {
+		t.Errorf("Sum256Slices allocs = %v, want 0", n)
+	}
+}
+
+func TestLen(t *testing.T) {
+	for _, newHash := range []func() hash.Hash{New, New224, NewStrict, func() hash.Hash { return NewTagged("tag") }, func() hash.Hash { return new(Digest) }} {
+		h := newHash().(interface {
+			hash.Hash
+			Len() uint64
+		})
+		if n := h.Len(); n != 0 {
+			t.Errorf("Len of a new hash = %d, want 0", n)
+		}
+		for _, n := range []int{1, 63, 64, 1000} {
+			want := h.Len() + uint64(n)
+			h.Write(make([]byte, n))
+			if got := h.Len(); got != want {
+				t.Errorf("Len after writing %d more bytes = %d, want %d", n, got, want)
+			}
+		}
+		before := h.Len()
+		h.Sum(nil)
+		if got := h.Len(); got != before {
+			t.Errorf("Len after Sum = %d, want %d", got, before)
+		}
+		h.Reset()
+		if n := h.Len(); n != 0 {
+			t.Errorf("Len after Reset = %d, want 0", n)
+		}
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	if err := SelfTest(); err != nil {
+		t.Fatalf("SelfTest() = %v", err)
+	}
+
+	// A wrong known answer must be reported.
+	saved := selfTests[0].out
+	defer func() { selfTests[0].out = saved }()
+	selfTests[0].out = "\x00" + saved[1:]
+	if err := SelfTest(); err == nil {
+		t.Error("SelfTest() with a corrupted known answer = nil")
+	}
+}
+
+func TestPool(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		h := Get()
+		if h.Size() != Size {
+			t.Fatalf("Get().Size() = %d, want %d", h.Size(), Size)
+		}
+		io.WriteString(h, "abc")
+		want := Sum256([]byte("abc"))
+		if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Errorf("Get() hash of abc = %x, want %x", got, want)
+		}
+		d := h.(*digest)
+		Put(h)
+		if *d != (digest{}) {
+			t.Errorf("Put did not zero the digest: %+v", *d)
+		}
+	}
+
+	// SHA-224 digests are reset to SHA-256 by Get.
+	h := New224()
+	io.WriteString(h, "abc")
+	Put(h)
+	h = Get()
+	if h.Size() != Size {
+		t.Errorf("Get().Size() after Put(New224()) = %d, want %d", h.Size(), Size)
+	}
+	Put(h)
+
+	// So are digests made by NewFromState and Clone.
+	h = NewFromState(Sum256(nil), BlockSize)
+	Put(h.(interface{ Clone() hash.Hash }).Clone())
+	Put(h)
+	for i := 0; i < 2; i++ {
+		h = Get()
+		io.WriteString(h, "abc")
+		if got, want := h.Sum(nil), Sum256([]byte("abc")); !bytes.Equal(got, want[:]) {
+			t.Errorf("Get() after Put(NewFromState(...)) hash of abc = %x, want %x", got, want)
+		}
+	}
+
+	// Other hashes are ignored.
+	Put(NewStrict())
+	Put(NewHMAC(nil))
+	Put(nil)
+}
+
+func TestWipe(t *testing.T) {
+	h := New()
+	io.WriteString(h, "secret data that is longer than one block of the hash, "+
+		"so that the chaining value has been updated")
+	d := h.(*digest)
+	d.Wipe()
+	for i, b := range d.x {
+		if b != 0 {
+			t.Fatalf("Wipe left x[%d] = %#x", i, b)
+		}
+	}
+	if d.nx != 0 || d.len != 0 {
+		t.Errorf("Wipe left nx = %d, len = %d", d.nx, d.len)
+	}
+	fresh := New().(*digest)
+	if d.h != fresh.h {
+		t.Errorf("Wipe left chaining value %x, want %x", d.h, fresh.h)
+	}
+
+	io.WriteString(h, "abc")
+	want := Sum256([]byte("abc"))
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("Sum after Wipe = %x, want %x", got, want)
+	}
+	h.Reset()
+	io.WriteString(h, "abc")
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("Sum after Wipe and Reset = %x, want %x", got, want)
+	}
+
+	// Wipe resets the wrappers of digest the way their Reset does.
+	type wiper interface {
+		hash.Hash
+		Wipe()
+	}
+	s := NewStrict().(wiper)
+	io.WriteString(s, "secret")
+	s.Sum(nil)
+	s.Wipe()
+	io.WriteString(s, "abc") // must not panic
+	if got := s.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("NewStrict: Sum after Wipe = %x, want %x", got, want)
+	}
+	tagged := NewTagged("tag").(wiper)
+	io.WriteString(tagged, "secret")
+	tagged.Wipe()
+	io.WriteString(tagged, "abc")
+	ref := NewTagged("tag")
+	io.WriteString(ref, "abc")
+	if got, want := tagged.Sum(nil), ref.Sum(nil); !bytes.Equal(got, want) {
+		t.Errorf("NewTagged: Sum after Wipe = %x, want %x", got, want)
+	}
+}
+
+func TestSumWithLen(t *testing.T) {
+	h := New()
+	var all []byte
+	for _, s := range []string{"", "a", "bc", strings.Repeat("d", 100), "efg"} {
+		io.WriteString(h, s)
+		all = append(all, s...)
+		sum, n := h.(interface {
+			SumWithLen([]byte) ([]byte, uint64)
+		}).SumWithLen([]byte("prefix"))
+		if n != uint64(len(all)) {
+			t.Errorf("SumWithLen length = %d, want %d", n, len(all))
+		}
+		want := Sum256(all)
+		if !bytes.Equal(sum, append([]byte("prefix"), want[:]...)) {
+			t.Errorf("SumWithLen = %x, want prefix and %x", sum, want)
+		}
+	}
+}
+
+var sum256DoubleTests = []struct {
+	in  string // hex
+	out string
+}{
+	{"", "5df6e0e2761359d30a8275058e299fcc0381534545f55cf43e41983f5d4c9456"},
+	{"68656c6c6f", "9595c9df90075148eb06860365df33584b75bff782a510c6cd4883a419833d50"},
+	// Bitcoin genesis block header. Block explorers show its hash
+	// reversed, as 000000000019d6689c085ae165831e93...
+	{
+		"0100000000000000000000000000000000000000000000000000000000000000" +
+			"000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa" +
+			"4b1e5e4a29ab5f49ffff001d1dac2b7c",
+		"6fe28c0ab6f1b372c1a6a246ae63f74f931e8365e15a089c68d6190000000000",
+	},
+	// Bitcoin block 1 header.
+	{
+		"010000006fe28c0ab6f1b372c1a6a246ae63f74f931e8365e15a089c68d61900" +
+			"00000000982051fd1e4ba744bbbe680e1fee14677ba1a3c3540bf7b1cdb606e8" +
+			"57233e0e61bc6649ffff001d01e36299",
+		"4860eb18bf1b1620e37e9490fc8a427514416fd75159ab86688e9a8300000000",
+	},
+}
+
+func TestSum256Double(t *testing.T) {
+	for _, tt := range sum256DoubleTests {
+		in, err := hex.DecodeString(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := fmt.Sprintf("%x", Sum256Double(in)); s != tt.out {
+			t.Errorf("Sum256Double(%s) = %s want %s", tt.in, s, tt.out)
+		}
+	}
+
+	for n := 0; n < 200; n += 13 {
+		first := Sum256(buf[:n])
+		if got, want := Sum256Double(buf[:n]), Sum256(first[:]); got != want {
+			t.Errorf("Sum256Double of %d bytes = %x, want %x", n, got, want)
+		}
+	}
+
+	in := []byte("hello")
+	if n := testing.AllocsPerRun(100, func() { Sum256Double(in) }); n > 0 {
+		t.Errorf("Sum256Double allocs = %v, want 0", n)
+	}
+}
+
+func TestResetAs(t *testing.T) {
+	type switcher interface {
+		hash.Hash
+		ResetAs224()
+		ResetAs256()
+	}
+	h := New().(switcher)
+	for i := 0; i < 2; i++ {
+		io.WriteString(h, "junk")
+		h.ResetAs224()
+		if h.Size() != Size224 {
+			t.Errorf("Size after ResetAs224 = %d, want %d", h.Size(), Size224)
+		}
+		io.WriteString(h, "abc")
+		want224 := Sum224([]byte("abc"))
+		if got := h.Sum(nil); !bytes.Equal(got, want224[:]) {
+			t.Errorf("Sum after ResetAs224 = %x, want %x", got, want224)
+		}
+
+		h.ResetAs256()
+		if h.Size() != Size {
+			t.Errorf("Size after ResetAs256 = %d, want %d", h.Size(), Size)
+		}
+		io.WriteString(h, "abc")
+		want := Sum256([]byte("abc"))
+		if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Errorf("Sum after ResetAs256 = %x, want %x", got, want)
+		}
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	data := make([]byte, 5*BlockSize)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	h := New()
+	c := h.(interface{ Checkpoint() [Size]byte })
+	for n := 0; n < len(data); {
+		if got, want := c.Checkpoint(), Sum256(data[:n]); got != want {
+			t.Errorf("Checkpoint after %d bytes = %x, want %x", n, got, want)
+		}
+		if got, want := c.Checkpoint(), Sum256(data[:n]); got != want {
+			t.Errorf("second Checkpoint after %d bytes = %x, want %x", n, got, want)
+		}
+		m := n + 37
+		if m > len(data) {
+			m = len(data)
+		}
+		h.Write(data[n:m])
+		n = m
+	}
+	want := Sum256(data)
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("Sum after Checkpoints = %x, want %x", got, want)
+	}
+
+	h = New224()
+	io.WriteString(h, "abc")
+	sum := h.(interface{ Checkpoint() [Size]byte }).Checkpoint()
+	if want := Sum224([]byte("abc")); !bytes.Equal(sum[:Size224], want[:]) || !bytes.Equal(sum[Size224:], make([]byte, Size-Size224)) {
+		t.Errorf("SHA224 Checkpoint = %x, want %x followed by zeros", sum, want)
+	}
+}
+
+func TestSumHex(t *testing.T) {
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	for _, n := range []int{0, 1, 64, 300} {
+		want := hex.EncodeToString(sum256(data[:n]))
+		if got := SumHex256(data[:n]); got != want {
+			t.Errorf("SumHex256(%d bytes) = %s, want %s", n, got, want)
+		}
+		sum := Sum224(data[:n])
+		if got, want := SumHex224(data[:n]), hex.EncodeToString(sum[:]); got != want {
+			t.Errorf("SumHex224(%d bytes) = %s, want %s", n, got, want)
+		}
+		h := New()
+		h.Write(data[:n])
+		if got := h.(interface{ SumHex() string }).SumHex(); got != want {
+			t.Errorf("SumHex() after %d bytes = %s, want %s", n, got, want)
+		}
+		// SumHex must not disturb the running hash.
+		h.Write([]byte("x"))
+		if got, want := h.Sum(nil), sum256(append(data[:n:n], 'x')); !bytes.Equal(got, want) {
+			t.Errorf("Sum after SumHex = %x, want %x", got, want)
+		}
+	}
+
+	h := New224()
+	h.Write([]byte("abc"))
+	want := Sum224([]byte("abc"))
+	if got := h.(interface{ SumHex() string }).SumHex(); got != hex.EncodeToString(want[:]) {
+		t.Errorf("SHA224 SumHex() = %s, want %x", got, want)
+	}
+
+	for _, g := range golden224 {
+		if got := SumHex224([]byte(g.in)); got != g.out {
+			t.Errorf("SumHex224(%q) = %s, want %s", g.in, got, g.out)
+		}
+	}
+	if n := testing.AllocsPerRun(10, func() { SumHex256(data) }); n > 1 {
+		t.Errorf("SumHex256 allocates %v times, want 1", n)
+	}
+}
+
+// sum256 is Sum256 returning a slice.
+func sum256(data []byte) []byte {
+	sum := Sum256(data)
+	return sum[:]
+}
+
+func TestNewCounting(t *testing.T) {
+	data := make([]byte, 200)
+	tests := []struct {
+		writes []int
+		blocks uint64
+	}{
+		{nil, 1},
+		{[]int{200}, 4},        // 3 full blocks, 8 bytes and padding in 1 block
+		{[]int{100, 1, 99}, 4}, // the same in pieces
+		{[]int{60}, 2},         // 60 bytes and padding need 2 blocks
+		{[]int{55}, 1},         // 55 bytes and padding fit in 1 block
+		{[]int{64, 64}, 3},     // 2 full blocks and padding
+	}
+	for _, tt := range tests {
+		h, blocks := NewCounting()
+		n := 0
+		for _, w := range tt.writes {
+			h.Write(data[n : n+w])
+			n += w
+		}
+		want := Sum256(data[:n])
+		if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Errorf("NewCounting hash of %d bytes = %x, want %x", n, got, want)
+		}
+		if *blocks != tt.blocks {
+			t.Errorf("NewCounting blocks for writes %v = %d, want %d", tt.writes, *blocks, tt.blocks)
+		}
+	}
+}
+
+func TestNewCountingFinalizers(t *testing.T) {
+	h, blocks := NewCounting()
+	h.Write(make([]byte, 60))
+	if *blocks != 0 {
+		t.Fatalf("NewCounting blocks after 60 bytes = %d, want 0", *blocks)
+	}
+	h.(interface{ SumHex() string }).SumHex()
+	if *blocks != 2 {
+		t.Errorf("NewCounting blocks after SumHex of 60 bytes = %d, want 2", *blocks)
+	}
+	h.(interface{ Words() [8]uint32 }).Words()
+	if *blocks != 4 {
+		t.Errorf("NewCounting blocks after Words of 60 bytes = %d, want 4", *blocks)
+	}
+
+	c := h.(interface{ Clone() hash.Hash }).Clone()
+	c.Write(make([]byte, 64))
+	if *blocks != 4 {
+		t.Errorf("NewCounting blocks after writing to a clone = %d, want 4", *blocks)
+	}
+
+	h.(*countingDigest).len = maxMessageLen - 10
+	if _, err := h.Write(make([]byte, 128)); err != ErrMessageTooLong {
+		t.Fatalf("Write past the length limit: err = %v, want ErrMessageTooLong", err)
+	}
+	if *blocks != 4 {
+		t.Errorf("NewCounting blocks after a refused Write = %d, want 4", *blocks)
+	}
+}
+
+func TestSumReuse(t *testing.T) {
+	type reuser interface {
+		hash.Hash
+		SumReuse([Size]byte) [Size]byte
+	}
+	data := make([]byte, 200)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	h := New().(reuser)
+	for _, n := range []int{0, 1, 55, 56, 64, 200} {
+		h.Reset()
+		h.Write(data[:n])
+		if got, want := h.SumReuse([Size]byte{}), Sum256(data[:n]); got != want {
+			t.Errorf("SumReuse after %d bytes = %x, want %x", n, got, want)
+		}
+	}
+
+	h = New224().(reuser)
+	h.Write([]byte("abc"))
+	var dst [Size]byte
+	for i := range dst {
+		dst[i] = 0xff
+	}
+	sum := h.SumReuse(dst)
+	if want := Sum224([]byte("abc")); !bytes.Equal(sum[:Size224], want[:]) || !bytes.Equal(sum[Size224:], dst[Size224:]) {
+		t.Errorf("SHA224 SumReuse = %x, want %x followed by the rest of dst", sum, want)
+	}
+}
+
+func TestSumTo(t *testing.T) {
+	type summerTo interface {
+		hash.Hash
+		SumTo([]byte) int
+	}
+	data := make([]byte, 200)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	for _, n := range []int{0, 1, 55, 56, 64, 200} {
+		want := Sum256(data[:n])
+		for _, h := range []hash.Hash{New(), NewStrict()} {
+			h.Write(data[:n])
+			dst := make([]byte, Size+1)
+			if got := h.(summerTo).SumTo(dst); got != Size || !bytes.Equal(dst[:Size], want[:]) || dst[Size] != 0 {
+				t.Errorf("%T: SumTo after %d bytes = %d, %x, want %d, %x", h, n, got, dst, Size, want)
+			}
+		}
+	}
+
+	h := New224().(summerTo)
+	h.Write([]byte("abc"))
+	dst := make([]byte, Size224)
+	if got, want := h.SumTo(dst), Sum224([]byte("abc")); got != Size224 || !bytes.Equal(dst, want[:]) {
+		t.Errorf("SHA224 SumTo = %d, %x, want %d, %x", got, dst, Size224, want)
+	}
+
+	// A frozen hash is finalized on a copy.
+	h = New().(summerTo)
+	h.Write([]byte("abc"))
+	h.(interface{ Freeze() }).Freeze()
+	dst = make([]byte, Size)
+	h.SumTo(dst)
+	if want := Sum256([]byte("abc")); !bytes.Equal(h.Sum(nil), want[:]) || !bytes.Equal(dst, want[:]) {
+		t.Errorf("SumTo on a frozen hash changed its state")
+	}
+
+	// The strict hash refuses writes after SumTo.
+	s := NewStrict()
+	s.(summerTo).SumTo(make([]byte, Size))
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("NewStrict: Write after SumTo did not panic")
+			}
+		}()
+		s.Write(nil)
+	}()
+
+	// The counting hash counts the padding blocks.
+	c, blocks := NewCounting()
+	c.Write(data[:56])
+	c.(summerTo).SumTo(make([]byte, Size))
+	if *blocks != 2 {
+		t.Errorf("NewCounting blocks after SumTo of 56 bytes = %d, want 2", *blocks)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("SumTo with a short destination did not panic")
+		}
+	}()
+	New().(summerTo).SumTo(make([]byte, Size-1))
+}
+
+func TestWriteByte(t *testing.T) {
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i*31 + 7)
+	}
+	for _, newHash := range []func() hash.Hash{New, New224, NewStrict} {
+		for _, n := range []int{0, 1, 55, 56, 63, 64, 65, 128, 300} {
+			want := newHash()
+			want.Write(data[:n])
+			h := newHash()
+			bw, ok := h.(io.ByteWriter)
+			if !ok {
+				t.Fatalf("%T does not implement io.ByteWriter", h)
+			}
+			for _, c := range data[:n] {
+				if err := bw.WriteByte(c); err != nil {
+					t.Fatalf("WriteByte: %v", err)
+				}
+			}
+			if got, want := h.Sum(nil), want.Sum(nil); !bytes.Equal(got, want) {
+				t.Errorf("%T: %d calls to WriteByte = %x, want %x", h, n, got, want)
+			}
+		}
+	}
+
+	h, blocks := NewCounting()
+	for _, c := range data[:200] {
+		h.(io.ByteWriter).WriteByte(c)
+	}
+	h.Sum(nil)
+	if *blocks != 4 {
+		t.Errorf("NewCounting blocks after 200 calls to WriteByte = %d, want 4", *blocks)
+	}
+}
+
+func TestParseState(t *testing.T) {
+	tests := []struct {
+		alg     string
+		newHash func() hash.Hash
+	}{
+		{"sha256", New},
+		{"sha224", New224},
+	}
+	for _, tt := range tests {
+		for _, n := range []int{0, 3, 64, 100, 1000} {
+			h := tt.newHash()
+			h.Write(make([]byte, n))
+			state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+			if err != nil {
+				t.Fatalf("could not marshal: %v", err)
+			}
+			if len(state) != MarshaledSize {
+				t.Errorf("len(MarshalBinary()) = %d, want MarshaledSize = %d", len(state), MarshaledSize)
+			}
+			alg, processedLen, err := ParseState(state)
+			if err != nil {
+				t.Fatalf("ParseState(%s state after %d bytes): %v", tt.alg, n, err)
+			}
+			if alg != tt.alg || processedLen != uint64(n) {
+				t.Errorf("ParseState(%s state after %d bytes) = %q, %d, want %q, %d", tt.alg, n, alg, processedLen, tt.alg, n)
+			}
+			if allocs := testing.AllocsPerRun(10, func() { ParseState(state) }); allocs > 0 {
+				t.Errorf("ParseState allocates %v times, want 0", allocs)
+			}
+		}
+	}
+
+	state, _ := New().(encoding.BinaryMarshaler).MarshalBinary()
+	corrupt := append([]byte(nil), state...)
+	corrupt[3] = 0x05
+	for _, b := range [][]byte{
+		nil,
+		state[:2],
+		state[:len(state)-1],
+		append(state[:len(state):len(state)], 0),
+		corrupt,
+		[]byte("md5\x01"),
+	} {
+		if _, _, err := ParseState(b); err == nil {
+			t.Errorf("ParseState(%q): no error when one was expected", b)
+		}
+	}
+}
+
+func TestNewLimited(t *testing.T) {
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	h := NewLimited(200)
+	for _, w := range []int{100, 64, 36} {
+		if n, err := h.Write(data[:w]); n != w || err != nil {
+			t.Fatalf("Write(%d bytes) under the limit = %d, %v", w, n, err)
+		}
+	}
+	want := New()
+	want.Write(data[:100])
+	want.Write(data[:64])
+	want.Write(data[:36])
+	if got := h.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+		t.Errorf("NewLimited hash at the limit = %x, want %x", got, want.Sum(nil))
+	}
+	if n, err := h.Write(nil); n != 0 || err != nil {
+		t.Errorf("empty Write at the limit = %d, %v, want 0, nil", n, err)
+	}
+	if n, err := h.Write(data[:1]); n != 0 || err != ErrLimitExceeded {
+		t.Errorf("Write past the limit = %d, %v, want 0, ErrLimitExceeded", n, err)
+	}
+	if err := h.(io.ByteWriter).WriteByte(0); err != ErrLimitExceeded {
+		t.Errorf("WriteByte past the limit = %v, want ErrLimitExceeded", err)
+	}
+	if got := h.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+		t.Errorf("NewLimited hash after writes past the limit = %x, want %x", got, want.Sum(nil))
+	}
+
+	// A write crossing the limit hashes the bytes up to it.
+	h.Reset()
+	h.Write(data[:150])
+	if n, err := h.Write(data[150:]); n != 50 || err != ErrLimitExceeded {
+		t.Errorf("Write crossing the limit = %d, %v, want 50, ErrLimitExceeded", n, err)
+	}
+	if got, want := h.Sum(nil), Sum256(data[:200]); !bytes.Equal(got, want[:]) {
+		t.Errorf("NewLimited hash after crossing the limit = %x, want %x", got, want)
+	}
+
+	h = NewLimited(0)
+	if n, err := h.Write(data[:1]); n != 0 || err != ErrLimitExceeded {
+		t.Errorf("Write to NewLimited(0) = %d, %v, want 0, ErrLimitExceeded", n, err)
+	}
+}
+
+func TestSum224Into(t *testing.T) {
+	type into interface {
+		hash.Hash
+		Sum224Into([]byte) int
+	}
+	for _, g := range golden224 {
+		want := Sum224([]byte(g.in))
+		dst := make([]byte, Size224+4)
+		if n := Sum224Into(dst, []byte(g.in)); n != Size224 || !bytes.Equal(dst[:n], want[:]) {
+			t.Errorf("Sum224Into(%q) = %d, %x, want %d, %x", g.in, n, dst[:n], Size224, want)
+		}
+
+		h := New224().(into)
+		h.Write([]byte(g.in))
+		dst = make([]byte, Size224)
+		if n := h.Sum224Into(dst); n != Size224 || !bytes.Equal(dst, want[:]) {
+			t.Errorf("Sum224Into method after writing %q = %d, %x, want %d, %x", g.in, n, dst, Size224, want)
+		}
+		if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Errorf("Sum after Sum224Into = %x, want %x", got, want)
+		}
+	}
+
+	if n := testing.AllocsPerRun(10, func() {
+		var dst [Size224]byte
+		Sum224Into(dst[:], buf[:100])
+	}); n > 0 {
+		t.Errorf("Sum224Into allocates %v times, want 0", n)
+	}
+
+	mustPanic := func(name string, f func()) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s did not panic", name)
+			}
+		}()
+		f()
+	}
+	short := make([]byte, Size224-1)
+	mustPanic("Sum224Into with a short destination", func() { Sum224Into(short, nil) })
+	mustPanic("Sum224Into method with a short destination", func() { New224().(into).Sum224Into(short) })
+	mustPanic("Sum224Into method on a SHA256 hash", func() { New().(into).Sum224Into(make([]byte, Size)) })
+}
+
+func TestSum256Block64(t *testing.T) {
+	var in [BlockSize]byte
+	for i := 0; i < 100; i++ {
+		rand.Read(in[:])
+		if got, want := Sum256Block64(&in), Sum256(in[:]); got != want {
+			t.Fatalf("Sum256Block64(%x) = %x, want %x", in, got, want)
+		}
+	}
+	in = [BlockSize]byte{}
+	if got, want := Sum256Block64(&in), Sum256(in[:]); got != want {
+		t.Errorf("Sum256Block64 of zeros = %x, want %x", got, want)
+	}
+}
+
+func TestTotalLen(t *testing.T) {
+	marshal := func(h hash.Hash, n int) []byte {
+		h.Write(make([]byte, n))
+		b, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			t.Fatalf("could not marshal: %v", err)
+		}
+		return b
+	}
+	states := [][]byte{marshal(New(), 0), marshal(New(), 100), marshal(New224(), 64), marshal(New(), 1000)}
+	if total, err := TotalLen(states...); total != 1164 || err != nil {
+		t.Errorf("TotalLen = %d, %v, want 1164, nil", total, err)
+	}
+	if total, err := TotalLen(); total != 0 || err != nil {
+		t.Errorf("TotalLen() = %d, %v, want 0, nil", total, err)
+	}
+
+	corrupt := append([]byte(nil), states[1]...)
+	corrupt[0] = 'x'
+	if _, err := TotalLen(states[0], corrupt, states[2]); err == nil {
+		t.Error("TotalLen with a corrupt state: no error when one was expected")
+	}
+	if _, err := TotalLen(states[0], states[1][:MarshaledSize-1]); err == nil {
+		t.Error("TotalLen with a truncated state: no error when one was expected")
+	}
+
+	huge := append([]byte(nil), states[0]...)
+	binary.BigEndian.PutUint64(huge[MarshaledSize-8:], maxMessageLen)
+	many := make([][]byte, 9)
+	for i := range many {
+		many[i] = huge
+	}
+	if _, err := TotalLen(many...); err == nil {
+		t.Error("TotalLen with an overflowing total: no error when one was expected")
+	}
+}
+
+func TestWords(t *testing.T) {
+	type worder interface {
+		hash.Hash
+		Words() [8]uint32
+	}
+	for _, newHash := range []func() hash.Hash{New, New224} {
+		for _, n := range []int{0, 3, 64, 100} {
+			h := newHash().(worder)
+			h.Write(buf[:n])
+			words := h.Words()
+			sum := h.Sum(nil)
+			for i := 0; i < len(sum)/4; i++ {
+				if want := binary.BigEndian.Uint32(sum[4*i:]); words[i] != want {
+					t.Errorf("Words() after %d bytes (size %d)[%d] = %#08x, want %#08x", n, h.Size(), i, words[i], want)
+				}
+			}
+			// Words must not finalize the hash itself.
+			h.Write(buf[:n])
+			want := newHash()
+			want.Write(buf[:n])
+			want.Write(buf[:n])
+			if got := h.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+				t.Errorf("Sum after Words = %x, want %x", got, want.Sum(nil))
+			}
+		}
+	}
+}
+
+func TestNewWithIV(t *testing.T) {
+	standard := [8]uint32{init0, init1, init2, init3, init4, init5, init6, init7}
+	for _, g := range golden {
+		h := NewWithIV(standard)
+		io.WriteString(h, g.in)
+		if s := fmt.Sprintf("%x", h.Sum(nil)); s != g.out {
+			t.Errorf("NewWithIV(standard IV) of %q = %s, want %s", g.in, s, g.out)
+		}
+	}
+
+	iv := standard
+	iv[3] ^= 1
+	h := NewWithIV(iv)
+	io.WriteString(h, "abc")
+	sum := h.Sum(nil)
+	if std := Sum256([]byte("abc")); bytes.Equal(sum, std[:]) {
+		t.Error("NewWithIV with a custom IV gave the standard checksum")
+	}
+	// The custom IV is loaded directly as the chaining value.
+	d := digest{h: iv}
+	d.Write([]byte("abc"))
+	if want := d.checkSum(); !bytes.Equal(sum, want[:]) {
+		t.Errorf("NewWithIV(%x) of \"abc\" = %x, want %x", iv, sum, want)
+	}
+	h.Reset()
+	io.WriteString(h, "abc")
+	if got := h.Sum(nil); !bytes.Equal(got, sum) {
+		t.Errorf("NewWithIV after Reset = %x, want %x", got, sum)
+	}
+	h2 := NewWithIV(iv)
+	io.WriteString(h2, "abc")
+	if got := h2.Sum(nil); !bytes.Equal(got, sum) {
+		t.Errorf("second NewWithIV with the same IV = %x, want %x", got, sum)
+	}
+}
+
+func TestDigest(t *testing.T) {
+	var _ hash.Hash = (*Digest)(nil)
+	for _, g := range golden {
+		var d Digest
+		io.WriteString(&d, g.in[:len(g.in)/2])
+		io.WriteString(&d, g.in[len(g.in)/2:])
+		if s := fmt.Sprintf("%x", d.Sum(nil)); s != g.out {
+			t.Errorf("Digest of %q = %s, want %s", g.in, s, g.out)
+		}
+		d.Reset()
+		io.WriteString(&d, g.in)
+		if s := fmt.Sprintf("%x", d.Sum(nil)); s != g.out {
+			t.Errorf("Digest of %q after Reset = %s, want %s", g.in, s, g.out)
+		}
+	}
+
+	var d Digest
+	if s := fmt.Sprintf("%x", d.Sum(nil)); s != golden[0].out {
+		t.Errorf("Sum of a zero Digest = %s, want %s", s, golden[0].out)
+	}
+	if s := d.String(); s != golden[0].out {
+		t.Errorf("String of a zero Digest = %s, want %s", s, golden[0].out)
+	}
+	io.WriteString(&d, "abc")
+	if s, want := fmt.Sprint(&d), SumHex256([]byte("abc")); s != want {
+		t.Errorf("Digest printed with %%v = %s, want %s", s, want)
+	}
+	if s, want := fmt.Sprintf("%x", d.Sum(nil)), SumHex256([]byte("abc")); s != want {
+		t.Errorf("Sum after String = %s, want %s", s, want)
+	}
+	d.Reset()
+	if d.Size() != Size || d.BlockSize() != BlockSize {
+		t.Errorf("Digest Size, BlockSize = %d, %d, want %d, %d", d.Size(), d.BlockSize(), Size, BlockSize)
+	}
+
+	sum := make([]byte, 0, Size)
+	if n := testing.AllocsPerRun(10, func() {
+		var d Digest
+		d.Write(buf[:100])
+		d.Sum(sum)
+	}); n > 0 {
+		t.Errorf("Digest allocates %v times, want 0", n)
+	}
+}
+
+func TestWriteString(t *testing.T) {
+	long := strings.Repeat("0123456789abcdef", 20)
+	newHashes := []func() hash.Hash{
+		New,
+		New224,
+		NewStrict,
+		func() hash.Hash { h, _ := NewCounting(); return h },
+		func() hash.Hash { return NewLimited(1 << 20) },
+		func() hash.Hash { return new(Digest) },
+	}
+	for _, newHash := range newHashes {
+		for _, n := range []int{0, 1, 63, 64, 65, 200, len(long)} {
+			want := newHash()
+			want.Write([]byte(long[:3]))
+			want.Write([]byte(long[:n]))
+			h := newHash()
+			sw, ok := h.(io.StringWriter)
+			if !ok {
+				t.Fatalf("%T does not implement io.StringWriter", h)
+			}
+			sw.WriteString(long[:3])
+			if nn, err := sw.WriteString(long[:n]); nn != n || err != nil {
+				t.Errorf("%T: WriteString of %d bytes = %d, %v", h, n, nn, err)
+			}
+			if got := h.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+				t.Errorf("%T: WriteString of %d bytes = %x, want %x", h, n, got, want.Sum(nil))
+			}
+		}
+	}
+
+	if n := testing.AllocsPerRun(10, func() {
+		var d Digest
+		d.WriteString(long)
+	}); n > 0 {
+		t.Errorf("WriteString allocates %v times, want 0", n)
+	}
+
+	h, blocks := NewCounting()
+	io.WriteString(h, long)
+	if want := uint64(len(long) / chunk); *blocks != want {
+		t.Errorf("NewCounting blocks after WriteString = %d, want %d", *blocks, want)
+	}
+
+	h = NewLimited(100)
+	if n, err := io.WriteString(h, long); n != 100 || err != ErrLimitExceeded {
+		t.Errorf("WriteString crossing the limit = %d, %v, want 100, ErrLimitExceeded", n, err)
+	}
+	if got, want := h.Sum(nil), Sum256([]byte(long[:100])); !bytes.Equal(got, want[:]) {
+		t.Errorf("NewLimited hash after WriteString = %x, want %x", got, want)
+	}
+
+	frozen := New()
+	frozen.(interface{ Freeze() }).Freeze()
+	if _, err := io.WriteString(frozen, "x"); err == nil {
+		t.Error("WriteString to a frozen hash: no error when one was expected")
+	}
+}
+
+func TestClone(t *testing.T) {
+	type cloner interface {
+		hash.Hash
+		Clone() hash.Hash
+	}
+	iv := [8]uint32{1, 2, 3, 4, 5, 6, 7, 8}
+	newHashes := map[string]func() hash.Hash{
+		"New":        New,
+		"New224":     New224,
+		"NewStrict":  NewStrict,
+		"NewLimited": func() hash.Hash { return NewLimited(1000) },
+		"NewWithIV":  func() hash.Hash { return NewWithIV(iv) },
+		"NewCounting": func() hash.Hash {
+			h, _ := NewCounting()
+			return h
+		},
+	}
+	prefix := buf[:100]
+	for name, newHash := range newHashes {
+		h := newHash()
+		h.Write(prefix)
+		c, ok := h.(cloner)
+		if !ok {
+			t.Fatalf("%s: %T has no Clone method", name, h)
+		}
+		fork := c.Clone()
+		if fmt.Sprintf("%T", fork) != fmt.Sprintf("%T", h) {
+			t.Errorf("%s: Clone returned a %T, want a %T", name, fork, h)
+		}
+		for _, suffix := range []string{"a", "bc"} {
+			want := newHash()
+			want.Write(prefix)
+			io.WriteString(want, suffix)
+			f := c.Clone()
+			io.WriteString(f, suffix)
+			if got := f.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+				t.Errorf("%s: clone after suffix %q = %x, want %x", name, suffix, got, want.Sum(nil))
+			}
+		}
+		// The clones must not have changed the original.
+		want := newHash()
+		want.Write(prefix)
+		if got := h.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+			t.Errorf("%s: original after cloning = %x, want %x", name, got, want.Sum(nil))
+		}
+		// Reset keeps the configuration of the hash.
+		fork.Reset()
+		want.Reset()
+		if got := fork.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+			t.Errorf("%s: clone after Reset = %x, want %x", name, got, want.Sum(nil))
+		}
+	}
+
+	h := New()
+	h.Write(prefix)
+	h.(interface{ Freeze() }).Freeze()
+	fork := h.(cloner).Clone()
+	if _, err := fork.Write([]byte("x")); err != nil {
+		t.Errorf("Write to a clone of a frozen hash: %v", err)
+	}
+}
+
+func TestMarshalCompact(t *testing.T) {
+	type compacter interface {
+		hash.Hash
+		encoding.BinaryUnmarshaler
+		MarshalCompact() ([]byte, error)
+	}
+	tests := []struct {
+		alg     string
+		newHash func() hash.Hash
+	}{
+		{"sha256", New},
+		{"sha224", New224},
+	}
+	for _, tt := range tests {
+		for _, n := range []int{0, 1, 63, 64, 65, 200} {
+			h := tt.newHash().(compacter)
+			h.Write(buf[:n])
+			state, err := h.MarshalCompact()
+			if err != nil {
+				t.Fatalf("MarshalCompact: %v", err)
+			}
+			if want := 44 + n%BlockSize; len(state) != want {
+				t.Errorf("%s: MarshalCompact after %d bytes is %d bytes long, want %d", tt.alg, n, len(state), want)
+			}
+			if alg, processedLen, err := ParseState(state); alg != tt.alg || processedLen != uint64(n) || err != nil {
+				t.Errorf("%s: ParseState of compact state after %d bytes = %q, %d, %v", tt.alg, n, alg, processedLen, err)
+			}
+
+			r := tt.newHash().(compacter)
+			if err := r.UnmarshalBinary(state); err != nil {
+				t.Fatalf("%s: UnmarshalBinary of compact state after %d bytes: %v", tt.alg, n, err)
+			}
+			h.Write([]byte("tail"))
+			r.Write([]byte("tail"))
+			if got, want := r.Sum(nil), h.Sum(nil); !bytes.Equal(got, want) {
+				t.Errorf("%s: resumed from compact state after %d bytes = %x, want %x", tt.alg, n, got, want)
+			}
+		}
+	}
+
+	h := New().(compacter)
+	h.Write(buf[:10])
+	state, _ := h.MarshalCompact()
+	wrongLen := append([]byte(nil), state...)
+	wrongLen[len(wrongLen)-11]++ // the low byte of the length
+	for _, b := range [][]byte{
+		state[:len(state)-1],
+		append(state[:len(state):len(state)], 0),
+		state[:compactHeaderSize-1],
+		wrongLen,
+	} {
+		if err := New().(compacter).UnmarshalBinary(b); err == nil {
+			t.Errorf("UnmarshalBinary(%x): no error when one was expected", b)
+		}
+		if _, _, err := ParseState(b); err == nil {
+			t.Errorf("ParseState(%x): no error when one was expected", b)
+		}
+	}
+	if err := New224().(compacter).UnmarshalBinary(state); err == nil {
+		t.Error("UnmarshalBinary of a SHA256 compact state into SHA224: no error when one was expected")
+	}
+}
+
+func TestNewTagged(t *testing.T) {
+	for _, tag := range []string{"", "BIP0340/challenge", "TapLeaf"} {
+		tagSum := Sum256([]byte(tag))
+		for _, n := range []int{0, 1, 55, 64, 200} {
+			msg := buf[:n]
+			in := append(append(append([]byte(nil), tagSum[:]...), tagSum[:]...), msg...)
+			want := Sum256(in)
+
+			h := NewTagged(tag)
+			h.Write(msg)
+			if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+				t.Errorf("NewTagged(%q) of %d bytes = %x, want %x", tag, n, got, want)
+			}
+			h.Reset()
+			h.Write(msg)
+			if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+				t.Errorf("NewTagged(%q) of %d bytes after Reset = %x, want %x", tag, n, got, want)
+			}
+		}
+	}
+
+	a, b := NewTagged("a"), NewTagged("b")
+	if bytes.Equal(a.Sum(nil), b.Sum(nil)) {
+		t.Error("NewTagged with different tags gave the same checksum")
+	}
+}
+
+func TestMessageTooLong(t *testing.T) {
+	d := New().(*digest)
+	d.len = maxMessageLen - 3
+	d.nx = int(d.len % chunk)
+	if n, err := d.Write(make([]byte, 4)); n != 0 || err != ErrMessageTooLong {
+		t.Errorf("Write past the limit = %d, %v, want 0, %v", n, err, ErrMessageTooLong)
+	}
+	if n, err := d.WriteString("abcd"); n != 0 || err != ErrMessageTooLong {
+		t.Errorf("WriteString past the limit = %d, %v, want 0, %v", n, err, ErrMessageTooLong)
+	}
+	if d.len != maxMessageLen-3 || d.nx != chunk-4 {
+		t.Fatalf("failed writes changed the state: len = %d, nx = %d", d.len, d.nx)
+	}
+	if n, err := d.Write(make([]byte, 2)); n != 2 || err != nil {
+		t.Errorf("Write up to the limit = %d, %v, want 2, nil", n, err)
+	}
+	if err := d.WriteByte(0); err != nil {
+		t.Errorf("WriteByte of the last byte: %v", err)
+	}
+	if err := d.WriteByte(0); err != ErrMessageTooLong {
+		t.Errorf("WriteByte past the limit = %v, want %v", err, ErrMessageTooLong)
+	}
+	// A message of the maximum length can still be finalized, and its
+	// length is encoded as 2^64-8 bits.
+	d0 := *d
+	d0.checkSum()
+	// The last byte leaves no room for the length, so it takes a second
+	// padding block.
+	want := *d
+	want.x[chunk-1] = 0x80
+	var last [chunk]byte
+	binary.BigEndian.PutUint64(last[chunk-8:], 1<<64-8)
+	block(&want, append(want.x[:], last[:]...))
+	if d0.h != want.h {
+		t.Errorf("checksum of a message of the maximum length does not encode its length")
+	}
+
+	// States past the limit cannot be restored either.
+	full, _ := New().(encoding.BinaryMarshaler).MarshalBinary()
+	binary.BigEndian.PutUint64(full[MarshaledSize-8:], maxMessageLen+1)
+	compact, _ := New().(*digest).MarshalCompact()
+	binary.BigEndian.PutUint64(compact[compactHeaderSize-8:], 1<<61)
+	for _, state := range [][]byte{full, compact} {
+		if err := New().(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != ErrMessageTooLong {
+			t.Errorf("UnmarshalBinary of a state past the limit = %v, want %v", err, ErrMessageTooLong)
+		}
+		if _, _, err := ParseState(state); err != ErrMessageTooLong {
+			t.Errorf("ParseState of a state past the limit = %v, want %v", err, ErrMessageTooLong)
+		}
+	}
+	d = New().(*digest)
+	d.len = 1<<64 - 61
+	d.nx = 3
+	text, _ := d.MarshalJSON()
+	if err := New().(json.Unmarshaler).UnmarshalJSON(text); err != ErrMessageTooLong {
+		t.Errorf("UnmarshalJSON of a state past the limit = %v, want %v", err, ErrMessageTooLong)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("NewFromState with a length past the limit did not panic")
+		}
+	}()
+	NewFromState([Size]byte{}, 1<<61)
+}
+
+func TestChain(t *testing.T) {
+	data := make([]byte, 200)
+	for i := range data {
+		data[i] = byte(i * 3)
+	}
+	prev := Sum256([]byte("genesis"))
+	for _, n := range []int{0, 1, 23, 24, 31, 32, 33, 96, 200} {
+		want := Sum256(append(append([]byte{}, prev[:]...), data[:n]...))
+		if got := Chain(prev, data[:n]); got != want {
+			t.Errorf("Chain(prev, %d bytes) = %x, want %x", n, got, want)
+		}
+		prev = want
+	}
+	if n := testing.AllocsPerRun(10, func() { prev = Chain(prev, data) }); n > 0 {
+		t.Errorf("Chain allocates %v times, want 0", n)
+	}
+}
+
+var bench = New()
+var buf = make([]byte, 8192)
+
+func benchmarkSize(b *testing.B, size int) {
+	b.SetBytes(int64(size))
+	sum := make([]byte, bench.Size())
+	for i := 0; i < b.N; i++ {
+		bench.Reset()
+		bench.Write(buf[:size])
+		bench.Sum(sum[:0])
+	}
+}
+
+func BenchmarkHash8Bytes(b *testing.B) {
+	benchmarkSize(b, 8)
+}
+
+func BenchmarkHash1K(b *testing.B) {
+	benchmarkSize(b, 1024)
+}
+
+func BenchmarkHash8K(b *testing.B) {
+	benchmarkSize(b, 8192)
+}
+
+func BenchmarkWriteAligned(b *testing.B) {
+	b.SetBytes(chunk)
+	bench.Reset()
+	for i := 0; i < b.N; i++ {
+		bench.Write(buf[:chunk])
+	}
+}
+
+func BenchmarkSum256And224(b *testing.B) {
+	b.SetBytes(int64(len(buf)))
+	for i := 0; i < b.N; i++ {
+		Sum256And224(buf)
+	}
+}
+
+func BenchmarkSum256And224Separate(b *testing.B) {
+	b.SetBytes(int64(len(buf)))
+	h256, h224 := New(), New224()
+	sum := make([]byte, Size)
+	for i := 0; i < b.N; i++ {
+		h256.Reset()
+		h224.Reset()
+		h256.Write(buf)
+		h224.Write(buf)
+		h256.Sum(sum[:0])
+		h224.Sum(sum[:0])
+	}
+}
+
+// hashSink makes the hashes in the allocation benchmarks escape, as they
+// do in a server that hands them to io.Copy.
+var hashSink hash.Hash
+
+func BenchmarkNewSum(b *testing.B) {
+	b.ReportAllocs()
+	sum := make([]byte, Size)
+	for i := 0; i < b.N; i++ {
+		h := New()
+		hashSink = h
+		h.Write(buf[:1024])
+		h.Sum(sum[:0])
+	}
+}
+
+func BenchmarkPoolSum(b *testing.B) {
+	b.ReportAllocs()
+	sum := make([]byte, Size)
+	for i := 0; i < b.N; i++ {
+		h := Get()
+		hashSink = h
+		h.Write(buf[:1024])
+		h.Sum(sum[:0])
+		Put(h)
+	}
+}
+
+func BenchmarkSumSmall(b *testing.B) {
+	h := New()
+	sum := make([]byte, 0, Size)
+	for i := 0; i < b.N; i++ {
+		h.Reset()
+		h.Write(buf[:8])
+		h.Sum(sum[:0])
+	}
+}
+
+func BenchmarkSumReuseSmall(b *testing.B) {
+	h := New().(interface {
+		hash.Hash
+		SumReuse([Size]byte) [Size]byte
+	})
+	var sum [Size]byte
+	for i := 0; i < b.N; i++ {
+		h.Reset()
+		h.Write(buf[:8])
+		sum = h.SumReuse(sum)
+	}
+}
+
+func BenchmarkSumToSmall(b *testing.B) {
+	h := New().(interface {
+		hash.Hash
+		SumTo([]byte) int
+	})
+	sum := make([]byte, Size)
+	for i := 0; i < b.N; i++ {
+		h.Reset()
+		h.Write(buf[:8])
+		h.SumTo(sum)
+	}
+}
+
+func benchmarkImplementation(b *testing.B, impl string) {
+	if err := SetImplementation(impl); err != nil {
+		b.Skip(err)
+	}
+	defer SetImplementation("auto")
+	benchmarkSize(b, 8192)
+}
+
+func BenchmarkImplShaNI(b *testing.B)   { benchmarkImplementation(b, "shani") }
+func BenchmarkImplAVX2(b *testing.B)    { benchmarkImplementation(b, "avx2") }
+func BenchmarkImplGeneric(b *testing.B) { benchmarkImplementation(b, "generic") }
+
+func BenchmarkWriteByte(b *testing.B) {
+	b.SetBytes(1024)
+	h := New().(io.ByteWriter)
+	for i := 0; i < b.N; i++ {
+		for _, c := range buf[:1024] {
+			h.WriteByte(c)
+		}
+	}
+}
+
+func BenchmarkWriteOneByte(b *testing.B) {
+	b.SetBytes(1024)
+	h := New()
+	for i := 0; i < b.N; i++ {
+		for j := range buf[:1024] {
+			h.Write(buf[j : j+1])
+		}
+	}
+}
+
+// sumSink keeps the checksums in benchmarks from being optimized away.
+var sumSink [Size]byte
+
+func BenchmarkSum256Block64(b *testing.B) {
+	var in [BlockSize]byte
+	copy(in[:], buf)
+	b.SetBytes(BlockSize)
+	for i := 0; i < b.N; i++ {
+		sumSink = Sum256Block64(&in)
+	}
+}
+
+func BenchmarkSum256Of64Bytes(b *testing.B) {
+	b.SetBytes(BlockSize)
+	for i := 0; i < b.N; i++ {
+		sumSink = Sum256(buf[:BlockSize])
+	}
+}
+
+func BenchmarkWriteString(b *testing.B) {
+	s := string(buf[:1024])
+	b.SetBytes(int64(len(s)))
+	var d Digest
+	for i := 0; i < b.N; i++ {
+		d.WriteString(s)
+	}
+}
+
+func BenchmarkWriteStringConvert(b *testing.B) {
+	s := string(buf[:1024])
+	b.SetBytes(int64(len(s)))
+	h := New()
+	for i := 0; i < b.N; i++ {
+		h.Write([]byte(s))
+	}
+}
+
+func BenchmarkSum256Double(b *testing.B) {
+	b.SetBytes(80)
+	for i := 0; i < b.N; i++ {
+		sumSink = Sum256Double(buf[:80])
+	}
+}
+
+func BenchmarkChain(b *testing.B) {
+	var prev [Size]byte
+	b.SetBytes(64)
+	for i := 0; i < b.N; i++ {
+		prev = Chain(prev, buf[:64])
+	}
+}
+
+func BenchmarkChainHash(b *testing.B) {
+	var prev [Size]byte
+	b.SetBytes(64)
+	for i := 0; i < b.N; i++ {
+		h := New()
+		h.Write(prev[:])
+		h.Write(buf[:64])
+		h.Sum(prev[:0])
+	}
 }
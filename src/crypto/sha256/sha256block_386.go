@@ -0,0 +1,16 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha256
+
+// The assembly implementation of block is always available on 386.
+var useAsm = true
+
+func implementations() []string {
+	return []string{"386", "generic"}
+}
+
+func setImplementation(impl string) {
+	useAsm = impl == "386"
+}
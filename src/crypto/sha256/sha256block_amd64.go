@@ -6,4 +6,59 @@ package sha256
 
 import "internal/cpu"
 
-var useAVX2 = cpu.X86.HasAVX2 && cpu.X86.HasBMI2
+var (
+	hasAVX2  = cpu.X86.HasAVX2 && cpu.X86.HasBMI2
+	hasSHANI = cpu.X86.HasSHA && cpu.X86.HasSSSE3 && cpu.X86.HasSSE41
+)
+
+// useSHANI selects blockSHANI. Otherwise blockAMD64 is used unless useAsm
+// is false, and useAVX2 selects between its two variants.
+var (
+	useSHANI = hasSHANI
+	useAVX2  = hasAVX2
+	useAsm   = true
+)
+
+//go:noescape
+func blockAMD64(dig *digest, p []byte)
+
+//go:noescape
+func blockSHANI(dig *digest, p []byte)
+
+func block(dig *digest, p []byte) {
+	switch {
+	case useSHANI:
+		blockSHANI(dig, p)
+	case useAsm:
+		blockAMD64(dig, p)
+	default:
+		blockGeneric(dig, p)
+	}
+}
+
+func implementations() []string {
+	var impls []string
+	if hasSHANI {
+		impls = append(impls, "shani")
+	}
+	if hasAVX512 {
+		impls = append(impls, "avx512")
+	}
+	if hasAVX2 {
+		impls = append(impls, "avx2")
+	}
+	return append(impls, "amd64", "generic")
+}
+
+// setImplementation selects impl. The avx512 implementation hashes single
+// messages like avx2 and differs only in the lanes of Sum256Batch.
+func setImplementation(impl string) {
+	useSHANI = impl == "shani"
+	useAVX2 = impl == "avx2" || impl == "avx512"
+	useAsm = impl != "generic"
+	useMulti = impl == "avx2" || impl == "avx512"
+	lanes = 8
+	if impl == "avx512" {
+		lanes = maxLanes
+	}
+}
@@ -550,7 +550,7 @@
 	;                                  \
 	ADDL  y3, h                        // h = t1 + S0 + MAJ					// --
 
-TEXT ·block(SB), 0, $536-32
+TEXT ·blockAMD64(SB), 0, $536-32
 	CMPB ·useAVX2(SB), $1
 	JE   avx2
 
@@ -862,6 +862,114 @@ done_hash:
 	VZEROUPPER
 	RET
 
+// The SHA-NI version follows the structure of Intel's reference code,
+// described in "Intel SHA Extensions: New Instructions Supporting the
+// Secure Hash Algorithm on Intel Architecture Processors" by Sean Gulley
+// et al. Each SHA256RNDS2 performs two rounds; the round inputs Kt + Wt
+// are passed implicitly in X0. The state is kept as ABEF in X1 and CDGH
+// in X2, and the message words Wt..Wt+3 of the last four quads in X3-X6.
+
+// Four rounds with the message words in m and the round constants of
+// quad c. K256 stores each group of four constants twice, hence c*32.
+#define SHANIROUNDS(m, c) \
+	MOVO        m, X0;              \
+	PADDD       (c*32)(AX), X0;     \
+	SHA256RNDS2 X0, X1, X2;         \
+	PSHUFD      $0x0e, X0, X0;      \
+	SHA256RNDS2 X0, X2, X1
+
+// Computes the next four message words into m0, which holds Wt-16..Wt-13
+// on entry, from the following quads m1, m2 and m3.
+#define SHANISCHEDULE(m0, m1, m2, m3) \
+	SHA256MSG1 m1, m0;              \ // m0 = Wt-16 + SIGMA0(Wt-15)
+	MOVO       m3, X8;              \
+	PALIGNR    $4, m2, X8;          \ // X8 = Wt-7..Wt-4
+	PADDD      X8, m0;              \
+	SHA256MSG2 m3, m0                  // m0 += SIGMA1(Wt-2)
+
+#define SHANILOAD(m, index) \
+	MOVOU  (index*16)(SI), m; \
+	PSHUFB X7, m
+
+// func blockSHANI(dig *digest, p []byte)
+TEXT ·blockSHANI(SB), NOSPLIT, $0-32
+	MOVQ dig+0(FP), DI
+	MOVQ p_base+8(FP), SI
+	MOVQ p_len+16(FP), DX
+	SHRQ $6, DX
+	SHLQ $6, DX
+	JEQ  shani_done
+	ADDQ SI, DX
+
+	// Reorder the state from H0..H7 into ABEF and CDGH.
+	MOVOU   (0*16)(DI), X1     // DCBA
+	MOVOU   (1*16)(DI), X2     // HGFE
+	PSHUFD  $0xb1, X1, X1      // CDAB
+	PSHUFD  $0x1b, X2, X2      // EFGH
+	MOVO    X1, X8
+	PALIGNR $8, X2, X1         // ABEF
+	PBLENDW $0xf0, X8, X2      // CDGH
+
+	MOVOU flip_mask<>(SB), X7
+	LEAQ  K256<>(SB), AX
+
+shani_loop:
+	MOVO X1, X9
+	MOVO X2, X10
+
+	SHANILOAD(X3, 0)
+	SHANIROUNDS(X3, 0)
+	SHANILOAD(X4, 1)
+	SHANIROUNDS(X4, 1)
+	SHANILOAD(X5, 2)
+	SHANIROUNDS(X5, 2)
+	SHANILOAD(X6, 3)
+	SHANIROUNDS(X6, 3)
+
+	SHANISCHEDULE(X3, X4, X5, X6)
+	SHANIROUNDS(X3, 4)
+	SHANISCHEDULE(X4, X5, X6, X3)
+	SHANIROUNDS(X4, 5)
+	SHANISCHEDULE(X5, X6, X3, X4)
+	SHANIROUNDS(X5, 6)
+	SHANISCHEDULE(X6, X3, X4, X5)
+	SHANIROUNDS(X6, 7)
+	SHANISCHEDULE(X3, X4, X5, X6)
+	SHANIROUNDS(X3, 8)
+	SHANISCHEDULE(X4, X5, X6, X3)
+	SHANIROUNDS(X4, 9)
+	SHANISCHEDULE(X5, X6, X3, X4)
+	SHANIROUNDS(X5, 10)
+	SHANISCHEDULE(X6, X3, X4, X5)
+	SHANIROUNDS(X6, 11)
+	SHANISCHEDULE(X3, X4, X5, X6)
+	SHANIROUNDS(X3, 12)
+	SHANISCHEDULE(X4, X5, X6, X3)
+	SHANIROUNDS(X4, 13)
+	SHANISCHEDULE(X5, X6, X3, X4)
+	SHANIROUNDS(X5, 14)
+	SHANISCHEDULE(X6, X3, X4, X5)
+	SHANIROUNDS(X6, 15)
+
+	PADDD X9, X1
+	PADDD X10, X2
+
+	ADDQ $64, SI
+	CMPQ SI, DX
+	JNE  shani_loop
+
+	// Reorder the state back into H0..H7.
+	PSHUFD  $0x1b, X1, X1      // FEBA
+	PSHUFD  $0xb1, X2, X2      // DCHG
+	MOVO    X1, X8
+	PBLENDW $0xf0, X2, X1      // DCBA
+	PALIGNR $8, X8, X2         // HGFE
+	MOVOU   X1, (0*16)(DI)
+	MOVOU   X2, (1*16)(DI)
+
+shani_done:
+	RET
+
 // shuffle byte order from LE to BE
 DATA flip_mask<>+0x00(SB)/8, $0x0405060700010203
 DATA flip_mask<>+0x08(SB)/8, $0x0c0d0e0f08090a0b
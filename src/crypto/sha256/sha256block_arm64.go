@@ -8,14 +8,27 @@ import "internal/cpu"
 
 var k = _K
 
+var useAsm = cpu.ARM64.HasSHA2
+
 //go:noescape
 func sha256block(h []uint32, p []byte, k []uint32)
 
 func block(dig *digest, p []byte) {
-	if !cpu.ARM64.HasSHA2 {
+	if !useAsm {
 		blockGeneric(dig, p)
 	} else {
 		h := dig.h[:]
 		sha256block(h, p, k)
 	}
 }
+
+func implementations() []string {
+	if cpu.ARM64.HasSHA2 {
+		return []string{"arm64", "generic"}
+	}
+	return []string{"generic"}
+}
+
+func setImplementation(impl string) {
+	useAsm = impl == "arm64"
+}
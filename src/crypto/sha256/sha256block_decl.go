@@ -2,10 +2,18 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// +build 386 amd64 s390x ppc64le
+// +build 386 ppc64le
 
 package sha256
 
 //go:noescape
 
-func block(dig *digest, p []byte)
+func blockAsm(dig *digest, p []byte)
+
+func block(dig *digest, p []byte) {
+	if useAsm {
+		blockAsm(dig, p)
+	} else {
+		blockGeneric(dig, p)
+	}
+}
@@ -7,3 +7,11 @@
 package sha256
 
 var block = blockGeneric
+
+const useAsm = false
+
+func implementations() []string {
+	return []string{"generic"}
+}
+
+func setImplementation(impl string) {}
@@ -7,3 +7,17 @@ package sha256
 import "internal/cpu"
 
 var useAsm = cpu.S390X.HasSHA256
+
+//go:noescape
+func block(dig *digest, p []byte)
+
+func implementations() []string {
+	if cpu.S390X.HasSHA256 {
+		return []string{"s390x", "generic"}
+	}
+	return []string{"generic"}
+}
+
+func setImplementation(impl string) {
+	useAsm = impl == "s390x"
+}
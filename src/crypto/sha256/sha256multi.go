@@ -0,0 +1,168 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Multi-buffer hashing: several independent messages are compressed in
+// lock step, one message per lane of a vector register.
+
+package sha256
+
+import "encoding/binary"
+
+// maxLanes is the largest number of messages blockMulti compresses at
+// once. The lane arrays are always this wide; blockMulti only uses the
+// first lanes of them, a number that depends on the implementation.
+const maxLanes = 16
+
+// Sum256Batch returns the SHA256 checksums of inputs, in order. The result
+// is the same as calling Sum256 on each input, but when the AVX2 block
+// implementation is in use on amd64, up to eight inputs are hashed in
+// parallel lanes, which is considerably faster for large numbers of small
+// inputs. The avx512 implementation, which is preferred to avx2 on CPUs
+// with AVX-512, hashes up to sixteen. CPUs with the SHA extensions hash a
+// single message about as fast as sixteen AVX-512 lanes do, so there the
+// inputs are hashed one at a time.
+func Sum256Batch(inputs [][]byte) [][Size]byte {
+	sums := make([][Size]byte, len(inputs))
+	if useMulti && len(inputs) >= minLanes() {
+		sumMulti(inputs, sums)
+		return sums
+	}
+	for i, in := range inputs {
+		sums[i] = Sum256(in)
+	}
+	return sums
+}
+
+// lane tracks the message being hashed in one lane.
+type lane struct {
+	idx  int    // index of the message in the batch, or -1 if idle
+	p    []byte // full blocks of the message not yet hashed
+	tail []byte // padded final blocks not yet hashed, a suffix of buf
+	buf  [2 * chunk]byte
+}
+
+// load sets up l to hash the message in, which has index idx.
+func (l *lane) load(in []byte, idx int) {
+	n := len(in) &^ (chunk - 1)
+	l.idx = idx
+	l.p = in[:n]
+	l.tail = padBlocks(&l.buf, in[n:], uint64(len(in)))
+}
+
+// next returns the next block of the message and reports whether it
+// is the last one.
+func (l *lane) next() (b []byte, last bool) {
+	if len(l.p) > 0 {
+		b, l.p = l.p[:chunk], l.p[chunk:]
+	} else {
+		b, l.tail = l.tail[:chunk], l.tail[chunk:]
+	}
+	return b, len(l.p) == 0 && len(l.tail) == 0
+}
+
+// minLanes returns the fewest busy lanes for which a blockMulti call is
+// still cheaper than finishing the remaining messages one at a time.
+func minLanes() int {
+	return lanes / 2
+}
+
+// sumMulti stores the SHA256 checksum of each of inputs in the
+// corresponding element of sums, using blockMulti while at least
+// minLanes messages are in flight.
+func sumMulti(inputs [][]byte, sums [][Size]byte) {
+	var (
+		h      [8][maxLanes]uint32
+		w      [16][maxLanes]uint32
+		buf    [maxLanes]lane
+		done   [maxLanes]bool
+		next   int
+		active int
+	)
+	ls := buf[:lanes]
+	for j := range ls {
+		ls[j].idx = -1
+	}
+	for {
+		// Refill idle lanes.
+		for j := range ls {
+			if ls[j].idx >= 0 || next == len(inputs) {
+				continue
+			}
+			ls[j].load(inputs[next], next)
+			h[0][j], h[1][j], h[2][j], h[3][j] = init0, init1, init2, init3
+			h[4][j], h[5][j], h[6][j], h[7][j] = init4, init5, init6, init7
+			next++
+			active++
+		}
+		if active < minLanes() {
+			break
+		}
+
+		// Transpose one block of every busy lane into w. Idle lanes
+		// hash whatever is left over in w; their result is ignored.
+		for j := range ls {
+			if ls[j].idx < 0 {
+				continue
+			}
+			var b []byte
+			b, done[j] = ls[j].next()
+			for i := range w {
+				w[i][j] = binary.BigEndian.Uint32(b[4*i:])
+			}
+		}
+		blockMulti(&h, &w)
+
+		for j := range ls {
+			if ls[j].idx < 0 || !done[j] {
+				continue
+			}
+			sum := &sums[ls[j].idx]
+			for i := range h {
+				binary.BigEndian.PutUint32(sum[4*i:], h[i][j])
+			}
+			ls[j].idx = -1
+			active--
+		}
+	}
+
+	// Finish the stragglers one lane at a time.
+	for j := range ls {
+		l := &ls[j]
+		if l.idx < 0 {
+			continue
+		}
+		var d digest
+		for i := range d.h {
+			d.h[i] = h[i][j]
+		}
+		if len(l.p) > 0 {
+			block(&d, l.p)
+		}
+		block(&d, l.tail)
+		sum := &sums[l.idx]
+		for i := range d.h {
+			binary.BigEndian.PutUint32(sum[4*i:], d.h[i])
+		}
+	}
+}
+
+// blockMultiGeneric compresses one block for each of the first lanes
+// messages whose chaining values are in h, transposed so that h[i][j] is
+// word i of lane j. The message words are in w in the same layout.
+func blockMultiGeneric(h *[8][maxLanes]uint32, w *[16][maxLanes]uint32) {
+	var d digest
+	var p [chunk]byte
+	for j := 0; j < lanes; j++ {
+		for i := range d.h {
+			d.h[i] = h[i][j]
+		}
+		for i := range w {
+			binary.BigEndian.PutUint32(p[4*i:], w[i][j])
+		}
+		blockGeneric(&d, p[:])
+		for i := range d.h {
+			h[i][j] = d.h[i]
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha256
+
+import "internal/cpu"
+
+// hasAVX512 reports whether blockMultiAVX512 can run. It also requires
+// AVX2, which the avx512 implementation uses for single messages.
+var hasAVX512 = cpu.X86.HasAVX512F && hasAVX2
+
+// useMulti is only set while the AVX2 or AVX-512 block implementation is
+// selected, see setImplementation.
+var useMulti = !hasSHANI && hasAVX2
+
+// lanes is the number of messages blockMulti compresses at once: eight
+// with AVX2 and sixteen with AVX-512.
+var lanes = defaultLanes()
+
+// defaultLanes returns lanes for the implementation selected at startup.
+func defaultLanes() int {
+	if !hasSHANI && hasAVX512 {
+		return maxLanes
+	}
+	return 8
+}
+
+//go:noescape
+func blockMultiAVX2(h *[8][maxLanes]uint32, w *[16][maxLanes]uint32, k []uint32)
+
+//go:noescape
+func blockMultiAVX512(h *[8][maxLanes]uint32, w *[16][maxLanes]uint32, k []uint32)
+
+func blockMulti(h *[8][maxLanes]uint32, w *[16][maxLanes]uint32) {
+	switch {
+	case lanes == maxLanes:
+		blockMultiAVX512(h, w, _K)
+	case hasAVX2:
+		blockMultiAVX2(h, w, _K)
+	default:
+		blockMultiGeneric(h, w)
+	}
+}
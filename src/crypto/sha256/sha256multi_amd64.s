@@ -0,0 +1,440 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+#include "textflag.h"
+
+// Multi-buffer SHA256 block routine for AVX2. See blockMultiGeneric in
+// sha256multi.go for the Go equivalent.
+//
+// Every YMM register holds the same state or message word of eight
+// independent messages, one message per 32-bit lane, so the scalar
+// algorithm from FIPS 180-4 runs on all eight messages in lock step.
+// The message schedule is kept in a 16-entry ring buffer that overwrites
+// the caller's message block w. Rows of h and w are maxLanes words long,
+// of which only the first eight are used.
+
+#define W DI // message schedule ring buffer
+#define K R8 // round constants
+
+#define T0 Y8
+#define T1 Y9
+#define T2 Y10
+#define T3 Y11
+#define T4 Y12
+
+// dst = x rotated right by n bits; t is clobbered.
+#define ROTR(x, n, t, dst) \
+	VPSRLD $(n), x, dst;    \
+	VPSLLD $(32-n), x, t;   \
+	VPOR   t, dst, dst
+
+// Wt = SIGMA1(Wt-2) + Wt-7 + SIGMA0(Wt-15) + Wt-16; for 16 <= t <= 63
+// wt, w2, w7 and w15 are the ring buffer slots of Wt, Wt-2, Wt-7 and
+// Wt-15. Wt-16 is read from slot wt before it is overwritten.
+#define MSGSCHEDULE(wt, w2, w7, w15) \
+	VMOVDQU (w15*64)(W), T0;    \
+	ROTR(T0, 7, T2, T1);        \
+	ROTR(T0, 18, T2, T3);       \
+	VPXOR   T3, T1, T1;         \
+	VPSRLD  $3, T0, T3;         \
+	VPXOR   T3, T1, T1;         \ // T1 = SIGMA0(Wt-15)
+	VPADDD  (wt*64)(W), T1, T1; \
+	VPADDD  (w7*64)(W), T1, T1; \
+	VMOVDQU (w2*64)(W), T0;     \
+	ROTR(T0, 17, T2, T3);       \
+	ROTR(T0, 19, T2, T4);       \
+	VPXOR   T4, T3, T3;         \
+	VPSRLD  $10, T0, T4;        \
+	VPXOR   T4, T3, T3;         \ // T3 = SIGMA1(Wt-2)
+	VPADDD  T3, T1, T1;         \ // T1 = Wt
+	VMOVDQU T1, (wt*64)(W)
+
+// T1 = h + BIGSIGMA1(e) + Ch(e,f,g) + Kt + Wt
+// T2 = BIGSIGMA0(a) + Maj(a,b,c)
+// d += T1
+// h = T1 + T2
+// The caller rotates the register arguments so that the new a is in h
+// and the new e is in d.
+#define ROUND(t, wt, a, b, c, d, e, f, g, h) \
+	ROTR(e, 6, T2, T0);           \
+	ROTR(e, 11, T2, T1);          \
+	VPXOR        T1, T0, T0;      \
+	ROTR(e, 25, T2, T1);          \
+	VPXOR        T1, T0, T0;      \ // T0 = BIGSIGMA1(e)
+	VPAND        f, e, T1;        \
+	VPANDN       g, e, T2;        \
+	VPXOR        T2, T1, T1;      \ // T1 = Ch(e,f,g)
+	VPADDD       T1, T0, T0;      \
+	VPADDD       h, T0, T0;       \
+	VPBROADCASTD (t*4)(K), T1;    \
+	VPADDD       T1, T0, T0;      \
+	VPADDD       (wt*64)(W), T0, T0; \ // T0 = T1 of FIPS 180-4
+	VPADDD       T0, d, d;        \
+	ROTR(a, 2, T2, T1);           \
+	ROTR(a, 13, T2, T3);          \
+	VPXOR        T3, T1, T1;      \
+	ROTR(a, 22, T2, T3);          \
+	VPXOR        T3, T1, T1;      \ // T1 = BIGSIGMA0(a)
+	VPOR         b, a, T2;        \
+	VPAND        c, T2, T2;       \
+	VPAND        b, a, T3;        \
+	VPOR         T3, T2, T2;      \ // T2 = Maj(a,b,c)
+	VPADDD       T2, T1, T1;      \
+	VPADDD       T1, T0, h
+
+// func blockMultiAVX2(h *[8][maxLanes]uint32, w *[16][maxLanes]uint32, k []uint32)
+TEXT ·blockMultiAVX2(SB), NOSPLIT, $0-40
+	MOVQ h+0(FP), SI
+	MOVQ w+8(FP), W
+	MOVQ k_base+16(FP), K
+
+	VMOVDQU (0*64)(SI), Y0 // a = H0
+	VMOVDQU (1*64)(SI), Y1 // b = H1
+	VMOVDQU (2*64)(SI), Y2 // c = H2
+	VMOVDQU (3*64)(SI), Y3 // d = H3
+	VMOVDQU (4*64)(SI), Y4 // e = H4
+	VMOVDQU (5*64)(SI), Y5 // f = H5
+	VMOVDQU (6*64)(SI), Y6 // g = H6
+	VMOVDQU (7*64)(SI), Y7 // h = H7
+
+	ROUND(0, 0, Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7)
+	ROUND(1, 1, Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6)
+	ROUND(2, 2, Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5)
+	ROUND(3, 3, Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4)
+	ROUND(4, 4, Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3)
+	ROUND(5, 5, Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2)
+	ROUND(6, 6, Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1)
+	ROUND(7, 7, Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0)
+	ROUND(8, 8, Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7)
+	ROUND(9, 9, Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6)
+	ROUND(10, 10, Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5)
+	ROUND(11, 11, Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4)
+	ROUND(12, 12, Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3)
+	ROUND(13, 13, Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2)
+	ROUND(14, 14, Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1)
+	ROUND(15, 15, Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0)
+	MSGSCHEDULE(0, 14, 9, 1)
+	ROUND(16, 0, Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7)
+	MSGSCHEDULE(1, 15, 10, 2)
+	ROUND(17, 1, Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6)
+	MSGSCHEDULE(2, 0, 11, 3)
+	ROUND(18, 2, Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5)
+	MSGSCHEDULE(3, 1, 12, 4)
+	ROUND(19, 3, Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4)
+	MSGSCHEDULE(4, 2, 13, 5)
+	ROUND(20, 4, Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3)
+	MSGSCHEDULE(5, 3, 14, 6)
+	ROUND(21, 5, Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2)
+	MSGSCHEDULE(6, 4, 15, 7)
+	ROUND(22, 6, Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1)
+	MSGSCHEDULE(7, 5, 0, 8)
+	ROUND(23, 7, Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0)
+	MSGSCHEDULE(8, 6, 1, 9)
+	ROUND(24, 8, Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7)
+	MSGSCHEDULE(9, 7, 2, 10)
+	ROUND(25, 9, Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6)
+	MSGSCHEDULE(10, 8, 3, 11)
+	ROUND(26, 10, Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5)
+	MSGSCHEDULE(11, 9, 4, 12)
+	ROUND(27, 11, Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4)
+	MSGSCHEDULE(12, 10, 5, 13)
+	ROUND(28, 12, Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3)
+	MSGSCHEDULE(13, 11, 6, 14)
+	ROUND(29, 13, Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2)
+	MSGSCHEDULE(14, 12, 7, 15)
+	ROUND(30, 14, Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1)
+	MSGSCHEDULE(15, 13, 8, 0)
+	ROUND(31, 15, Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0)
+	MSGSCHEDULE(0, 14, 9, 1)
+	ROUND(32, 0, Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7)
+	MSGSCHEDULE(1, 15, 10, 2)
+	ROUND(33, 1, Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6)
+	MSGSCHEDULE(2, 0, 11, 3)
+	ROUND(34, 2, Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5)
+	MSGSCHEDULE(3, 1, 12, 4)
+	ROUND(35, 3, Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4)
+	MSGSCHEDULE(4, 2, 13, 5)
+	ROUND(36, 4, Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3)
+	MSGSCHEDULE(5, 3, 14, 6)
+	ROUND(37, 5, Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2)
+	MSGSCHEDULE(6, 4, 15, 7)
+	ROUND(38, 6, Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1)
+	MSGSCHEDULE(7, 5, 0, 8)
+	ROUND(39, 7, Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0)
+	MSGSCHEDULE(8, 6, 1, 9)
+	ROUND(40, 8, Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7)
+	MSGSCHEDULE(9, 7, 2, 10)
+	ROUND(41, 9, Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6)
+	MSGSCHEDULE(10, 8, 3, 11)
+	ROUND(42, 10, Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5)
+	MSGSCHEDULE(11, 9, 4, 12)
+	ROUND(43, 11, Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4)
+	MSGSCHEDULE(12, 10, 5, 13)
+	ROUND(44, 12, Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3)
+	MSGSCHEDULE(13, 11, 6, 14)
+	ROUND(45, 13, Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2)
+	MSGSCHEDULE(14, 12, 7, 15)
+	ROUND(46, 14, Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1)
+	MSGSCHEDULE(15, 13, 8, 0)
+	ROUND(47, 15, Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0)
+	MSGSCHEDULE(0, 14, 9, 1)
+	ROUND(48, 0, Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7)
+	MSGSCHEDULE(1, 15, 10, 2)
+	ROUND(49, 1, Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6)
+	MSGSCHEDULE(2, 0, 11, 3)
+	ROUND(50, 2, Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5)
+	MSGSCHEDULE(3, 1, 12, 4)
+	ROUND(51, 3, Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4)
+	MSGSCHEDULE(4, 2, 13, 5)
+	ROUND(52, 4, Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3)
+	MSGSCHEDULE(5, 3, 14, 6)
+	ROUND(53, 5, Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2)
+	MSGSCHEDULE(6, 4, 15, 7)
+	ROUND(54, 6, Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1)
+	MSGSCHEDULE(7, 5, 0, 8)
+	ROUND(55, 7, Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0)
+	MSGSCHEDULE(8, 6, 1, 9)
+	ROUND(56, 8, Y0, Y1, Y2, Y3, Y4, Y5, Y6, Y7)
+	MSGSCHEDULE(9, 7, 2, 10)
+	ROUND(57, 9, Y7, Y0, Y1, Y2, Y3, Y4, Y5, Y6)
+	MSGSCHEDULE(10, 8, 3, 11)
+	ROUND(58, 10, Y6, Y7, Y0, Y1, Y2, Y3, Y4, Y5)
+	MSGSCHEDULE(11, 9, 4, 12)
+	ROUND(59, 11, Y5, Y6, Y7, Y0, Y1, Y2, Y3, Y4)
+	MSGSCHEDULE(12, 10, 5, 13)
+	ROUND(60, 12, Y4, Y5, Y6, Y7, Y0, Y1, Y2, Y3)
+	MSGSCHEDULE(13, 11, 6, 14)
+	ROUND(61, 13, Y3, Y4, Y5, Y6, Y7, Y0, Y1, Y2)
+	MSGSCHEDULE(14, 12, 7, 15)
+	ROUND(62, 14, Y2, Y3, Y4, Y5, Y6, Y7, Y0, Y1)
+	MSGSCHEDULE(15, 13, 8, 0)
+	ROUND(63, 15, Y1, Y2, Y3, Y4, Y5, Y6, Y7, Y0)
+
+	VPADDD  (0*64)(SI), Y0, Y0
+	VMOVDQU Y0, (0*64)(SI)
+	VPADDD  (1*64)(SI), Y1, Y1
+	VMOVDQU Y1, (1*64)(SI)
+	VPADDD  (2*64)(SI), Y2, Y2
+	VMOVDQU Y2, (2*64)(SI)
+	VPADDD  (3*64)(SI), Y3, Y3
+	VMOVDQU Y3, (3*64)(SI)
+	VPADDD  (4*64)(SI), Y4, Y4
+	VMOVDQU Y4, (4*64)(SI)
+	VPADDD  (5*64)(SI), Y5, Y5
+	VMOVDQU Y5, (5*64)(SI)
+	VPADDD  (6*64)(SI), Y6, Y6
+	VMOVDQU Y6, (6*64)(SI)
+	VPADDD  (7*64)(SI), Y7, Y7
+	VMOVDQU Y7, (7*64)(SI)
+
+	VZEROUPPER
+	RET
+
+// Multi-buffer SHA256 block routine for AVX-512. It is the same algorithm
+// as blockMultiAVX2 on sixteen messages, one per lane of a ZMM register.
+// AVX-512 has rotate and three-input logic instructions, and enough
+// registers to keep the whole message schedule in Z16-Z31, so the caller's
+// message block w is only read.
+
+// Wt = SIGMA1(Wt-2) + Wt-7 + SIGMA0(Wt-15) + Wt-16; for 16 <= t <= 63
+// wt holds Wt-16 and is overwritten with Wt.
+#define MSGSCHEDULE512(wt, w2, w7, w15) \
+	VPRORD     $7, w15, Z9;       \
+	VPRORD     $18, w15, Z10;     \
+	VPSRLD     $3, w15, Z11;      \
+	VPTERNLOGD $0x96, Z11, Z10, Z9; \ // Z9 = SIGMA0(Wt-15)
+	VPADDD     Z9, wt, wt;        \
+	VPADDD     w7, wt, wt;        \
+	VPRORD     $17, w2, Z9;       \
+	VPRORD     $19, w2, Z10;      \
+	VPSRLD     $10, w2, Z11;      \
+	VPTERNLOGD $0x96, Z11, Z10, Z9; \ // Z9 = SIGMA1(Wt-2)
+	VPADDD     Z9, wt, wt
+
+// The same round as ROUND, with Ch, Maj and the three-way XORs each done
+// by one VPTERNLOGD.
+#define ROUND512(t, wt, a, b, c, d, e, f, g, h) \
+	VPRORD      $6, e, Z8;           \
+	VPRORD      $11, e, Z9;          \
+	VPRORD      $25, e, Z10;         \
+	VPTERNLOGD  $0x96, Z10, Z9, Z8;  \ // Z8 = BIGSIGMA1(e)
+	VMOVDQA32   e, Z9;               \
+	VPTERNLOGD  $0xca, g, f, Z9;     \ // Z9 = Ch(e,f,g)
+	VPADDD      Z9, Z8, Z8;          \
+	VPADDD      h, Z8, Z8;           \
+	VPADDD.BCST (t*4)(K), Z8, Z8;    \
+	VPADDD      wt, Z8, Z8;          \ // Z8 = T1 of FIPS 180-4
+	VPADDD      Z8, d, d;            \
+	VPRORD      $2, a, Z9;           \
+	VPRORD      $13, a, Z10;         \
+	VPRORD      $22, a, Z11;         \
+	VPTERNLOGD  $0x96, Z11, Z10, Z9; \ // Z9 = BIGSIGMA0(a)
+	VMOVDQA32   a, Z10;              \
+	VPTERNLOGD  $0xe8, c, b, Z10;    \ // Z10 = Maj(a,b,c)
+	VPADDD      Z10, Z9, Z9;         \
+	VPADDD      Z9, Z8, h
+
+// func blockMultiAVX512(h *[8][maxLanes]uint32, w *[16][maxLanes]uint32, k []uint32)
+TEXT ·blockMultiAVX512(SB), NOSPLIT, $0-40
+	MOVQ h+0(FP), SI
+	MOVQ w+8(FP), W
+	MOVQ k_base+16(FP), K
+	VMOVDQU32 (0*64)(SI), Z0 // a = H0
+	VMOVDQU32 (1*64)(SI), Z1 // b = H1
+	VMOVDQU32 (2*64)(SI), Z2 // c = H2
+	VMOVDQU32 (3*64)(SI), Z3 // d = H3
+	VMOVDQU32 (4*64)(SI), Z4 // e = H4
+	VMOVDQU32 (5*64)(SI), Z5 // f = H5
+	VMOVDQU32 (6*64)(SI), Z6 // g = H6
+	VMOVDQU32 (7*64)(SI), Z7 // h = H7
+
+	VMOVDQU32 (0*64)(W), Z16
+	VMOVDQU32 (1*64)(W), Z17
+	VMOVDQU32 (2*64)(W), Z18
+	VMOVDQU32 (3*64)(W), Z19
+	VMOVDQU32 (4*64)(W), Z20
+	VMOVDQU32 (5*64)(W), Z21
+	VMOVDQU32 (6*64)(W), Z22
+	VMOVDQU32 (7*64)(W), Z23
+	VMOVDQU32 (8*64)(W), Z24
+	VMOVDQU32 (9*64)(W), Z25
+	VMOVDQU32 (10*64)(W), Z26
+	VMOVDQU32 (11*64)(W), Z27
+	VMOVDQU32 (12*64)(W), Z28
+	VMOVDQU32 (13*64)(W), Z29
+	VMOVDQU32 (14*64)(W), Z30
+	VMOVDQU32 (15*64)(W), Z31
+
+	ROUND512(0, Z16, Z0, Z1, Z2, Z3, Z4, Z5, Z6, Z7)
+	ROUND512(1, Z17, Z7, Z0, Z1, Z2, Z3, Z4, Z5, Z6)
+	ROUND512(2, Z18, Z6, Z7, Z0, Z1, Z2, Z3, Z4, Z5)
+	ROUND512(3, Z19, Z5, Z6, Z7, Z0, Z1, Z2, Z3, Z4)
+	ROUND512(4, Z20, Z4, Z5, Z6, Z7, Z0, Z1, Z2, Z3)
+	ROUND512(5, Z21, Z3, Z4, Z5, Z6, Z7, Z0, Z1, Z2)
+	ROUND512(6, Z22, Z2, Z3, Z4, Z5, Z6, Z7, Z0, Z1)
+	ROUND512(7, Z23, Z1, Z2, Z3, Z4, Z5, Z6, Z7, Z0)
+	ROUND512(8, Z24, Z0, Z1, Z2, Z3, Z4, Z5, Z6, Z7)
+	ROUND512(9, Z25, Z7, Z0, Z1, Z2, Z3, Z4, Z5, Z6)
+	ROUND512(10, Z26, Z6, Z7, Z0, Z1, Z2, Z3, Z4, Z5)
+	ROUND512(11, Z27, Z5, Z6, Z7, Z0, Z1, Z2, Z3, Z4)
+	ROUND512(12, Z28, Z4, Z5, Z6, Z7, Z0, Z1, Z2, Z3)
+	ROUND512(13, Z29, Z3, Z4, Z5, Z6, Z7, Z0, Z1, Z2)
+	ROUND512(14, Z30, Z2, Z3, Z4, Z5, Z6, Z7, Z0, Z1)
+	ROUND512(15, Z31, Z1, Z2, Z3, Z4, Z5, Z6, Z7, Z0)
+	MSGSCHEDULE512(Z16, Z30, Z25, Z17)
+	ROUND512(16, Z16, Z0, Z1, Z2, Z3, Z4, Z5, Z6, Z7)
+	MSGSCHEDULE512(Z17, Z31, Z26, Z18)
+	ROUND512(17, Z17, Z7, Z0, Z1, Z2, Z3, Z4, Z5, Z6)
+	MSGSCHEDULE512(Z18, Z16, Z27, Z19)
+	ROUND512(18, Z18, Z6, Z7, Z0, Z1, Z2, Z3, Z4, Z5)
+	MSGSCHEDULE512(Z19, Z17, Z28, Z20)
+	ROUND512(19, Z19, Z5, Z6, Z7, Z0, Z1, Z2, Z3, Z4)
+	MSGSCHEDULE512(Z20, Z18, Z29, Z21)
+	ROUND512(20, Z20, Z4, Z5, Z6, Z7, Z0, Z1, Z2, Z3)
+	MSGSCHEDULE512(Z21, Z19, Z30, Z22)
+	ROUND512(21, Z21, Z3, Z4, Z5, Z6, Z7, Z0, Z1, Z2)
+	MSGSCHEDULE512(Z22, Z20, Z31, Z23)
+	ROUND512(22, Z22, Z2, Z3, Z4, Z5, Z6, Z7, Z0, Z1)
+	MSGSCHEDULE512(Z23, Z21, Z16, Z24)
+	ROUND512(23, Z23, Z1, Z2, Z3, Z4, Z5, Z6, Z7, Z0)
+	MSGSCHEDULE512(Z24, Z22, Z17, Z25)
+	ROUND512(24, Z24, Z0, Z1, Z2, Z3, Z4, Z5, Z6, Z7)
+	MSGSCHEDULE512(Z25, Z23, Z18, Z26)
+	ROUND512(25, Z25, Z7, Z0, Z1, Z2, Z3, Z4, Z5, Z6)
+	MSGSCHEDULE512(Z26, Z24, Z19, Z27)
+	ROUND512(26, Z26, Z6, Z7, Z0, Z1, Z2, Z3, Z4, Z5)
+	MSGSCHEDULE512(Z27, Z25, Z20, Z28)
+	ROUND512(27, Z27, Z5, Z6, Z7, Z0, Z1, Z2, Z3, Z4)
+	MSGSCHEDULE512(Z28, Z26, Z21, Z29)
+	ROUND512(28, Z28, Z4, Z5, Z6, Z7, Z0, Z1, Z2, Z3)
+	MSGSCHEDULE512(Z29, Z27, Z22, Z30)
+	ROUND512(29, Z29, Z3, Z4, Z5, Z6, Z7, Z0, Z1, Z2)
+	MSGSCHEDULE512(Z30, Z28, Z23, Z31)
+	ROUND512(30, Z30, Z2, Z3, Z4, Z5, Z6, Z7, Z0, Z1)
+	MSGSCHEDULE512(Z31, Z29, Z24, Z16)
+	ROUND512(31, Z31, Z1, Z2, Z3, Z4, Z5, Z6, Z7, Z0)
+	MSGSCHEDULE512(Z16, Z30, Z25, Z17)
+	ROUND512(32, Z16, Z0, Z1, Z2, Z3, Z4, Z5, Z6, Z7)
+	MSGSCHEDULE512(Z17, Z31, Z26, Z18)
+	ROUND512(33, Z17, Z7, Z0, Z1, Z2, Z3, Z4, Z5, Z6)
+	MSGSCHEDULE512(Z18, Z16, Z27, Z19)
+	ROUND512(34, Z18, Z6, Z7, Z0, Z1, Z2, Z3, Z4, Z5)
+	MSGSCHEDULE512(Z19, Z17, Z28, Z20)
+	ROUND512(35, Z19, Z5, Z6, Z7, Z0, Z1, Z2, Z3, Z4)
+	MSGSCHEDULE512(Z20, Z18, Z29, Z21)
+	ROUND512(36, Z20, Z4, Z5, Z6, Z7, Z0, Z1, Z2, Z3)
+	MSGSCHEDULE512(Z21, Z19, Z30, Z22)
+	ROUND512(37, Z21, Z3, Z4, Z5, Z6, Z7, Z0, Z1, Z2)
+	MSGSCHEDULE512(Z22, Z20, Z31, Z23)
+	ROUND512(38, Z22, Z2, Z3, Z4, Z5, Z6, Z7, Z0, Z1)
+	MSGSCHEDULE512(Z23, Z21, Z16, Z24)
+	ROUND512(39, Z23, Z1, Z2, Z3, Z4, Z5, Z6, Z7, Z0)
+	MSGSCHEDULE512(Z24, Z22, Z17, Z25)
+	ROUND512(40, Z24, Z0, Z1, Z2, Z3, Z4, Z5, Z6, Z7)
+	MSGSCHEDULE512(Z25, Z23, Z18, Z26)
+	ROUND512(41, Z25, Z7, Z0, Z1, Z2, Z3, Z4, Z5, Z6)
+	MSGSCHEDULE512(Z26, Z24, Z19, Z27)
+	ROUND512(42, Z26, Z6, Z7, Z0, Z1, Z2, Z3, Z4, Z5)
+	MSGSCHEDULE512(Z27, Z25, Z20, Z28)
+	ROUND512(43, Z27, Z5, Z6, Z7, Z0, Z1, Z2, Z3, Z4)
+	MSGSCHEDULE512(Z28, Z26, Z21, Z29)
+	ROUND512(44, Z28, Z4, Z5, Z6, Z7, Z0, Z1, Z2, Z3)
+	MSGSCHEDULE512(Z29, Z27, Z22, Z30)
+	ROUND512(45, Z29, Z3, Z4, Z5, Z6, Z7, Z0, Z1, Z2)
+	MSGSCHEDULE512(Z30, Z28, Z23, Z31)
+	ROUND512(46, Z30, Z2, Z3, Z4, Z5, Z6, Z7, Z0, Z1)
+	MSGSCHEDULE512(Z31, Z29, Z24, Z16)
+	ROUND512(47, Z31, Z1, Z2, Z3, Z4, Z5, Z6, Z7, Z0)
+	MSGSCHEDULE512(Z16, Z30, Z25, Z17)
+	ROUND512(48, Z16, Z0, Z1, Z2, Z3, Z4, Z5, Z6, Z7)
+	MSGSCHEDULE512(Z17, Z31, Z26, Z18)
+	ROUND512(49, Z17, Z7, Z0, Z1, Z2, Z3, Z4, Z5, Z6)
+	MSGSCHEDULE512(Z18, Z16, Z27, Z19)
+	ROUND512(50, Z18, Z6, Z7, Z0, Z1, Z2, Z3, Z4, Z5)
+	MSGSCHEDULE512(Z19, Z17, Z28, Z20)
+	ROUND512(51, Z19, Z5, Z6, Z7, Z0, Z1, Z2, Z3, Z4)
+	MSGSCHEDULE512(Z20, Z18, Z29, Z21)
+	ROUND512(52, Z20, Z4, Z5, Z6, Z7, Z0, Z1, Z2, Z3)
+	MSGSCHEDULE512(Z21, Z19, Z30, Z22)
+	ROUND512(53, Z21, Z3, Z4, Z5, Z6, Z7, Z0, Z1, Z2)
+	MSGSCHEDULE512(Z22, Z20, Z31, Z23)
+	ROUND512(54, Z22, Z2, Z3, Z4, Z5, Z6, Z7, Z0, Z1)
+	MSGSCHEDULE512(Z23, Z21, Z16, Z24)
+	ROUND512(55, Z23, Z1, Z2, Z3, Z4, Z5, Z6, Z7, Z0)
+	MSGSCHEDULE512(Z24, Z22, Z17, Z25)
+	ROUND512(56, Z24, Z0, Z1, Z2, Z3, Z4, Z5, Z6, Z7)
+	MSGSCHEDULE512(Z25, Z23, Z18, Z26)
+	ROUND512(57, Z25, Z7, Z0, Z1, Z2, Z3, Z4, Z5, Z6)
+	MSGSCHEDULE512(Z26, Z24, Z19, Z27)
+	ROUND512(58, Z26, Z6, Z7, Z0, Z1, Z2, Z3, Z4, Z5)
+	MSGSCHEDULE512(Z27, Z25, Z20, Z28)
+	ROUND512(59, Z27, Z5, Z6, Z7, Z0, Z1, Z2, Z3, Z4)
+	MSGSCHEDULE512(Z28, Z26, Z21, Z29)
+	ROUND512(60, Z28, Z4, Z5, Z6, Z7, Z0, Z1, Z2, Z3)
+	MSGSCHEDULE512(Z29, Z27, Z22, Z30)
+	ROUND512(61, Z29, Z3, Z4, Z5, Z6, Z7, Z0, Z1, Z2)
+	MSGSCHEDULE512(Z30, Z28, Z23, Z31)
+	ROUND512(62, Z30, Z2, Z3, Z4, Z5, Z6, Z7, Z0, Z1)
+	MSGSCHEDULE512(Z31, Z29, Z24, Z16)
+	ROUND512(63, Z31, Z1, Z2, Z3, Z4, Z5, Z6, Z7, Z0)
+
+	VPADDD    (0*64)(SI), Z0, Z0
+	VMOVDQU32 Z0, (0*64)(SI)
+	VPADDD    (1*64)(SI), Z1, Z1
+	VMOVDQU32 Z1, (1*64)(SI)
+	VPADDD    (2*64)(SI), Z2, Z2
+	VMOVDQU32 Z2, (2*64)(SI)
+	VPADDD    (3*64)(SI), Z3, Z3
+	VMOVDQU32 Z3, (3*64)(SI)
+	VPADDD    (4*64)(SI), Z4, Z4
+	VMOVDQU32 Z4, (4*64)(SI)
+	VPADDD    (5*64)(SI), Z5, Z5
+	VMOVDQU32 Z5, (5*64)(SI)
+	VPADDD    (6*64)(SI), Z6, Z6
+	VMOVDQU32 Z6, (6*64)(SI)
+	VPADDD    (7*64)(SI), Z7, Z7
+	VMOVDQU32 Z7, (7*64)(SI)
+
+	VZEROUPPER
+	RET
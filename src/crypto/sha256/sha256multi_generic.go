@@ -0,0 +1,14 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !amd64
+
+package sha256
+
+const useMulti = false
+
+// lanes is the number of messages blockMulti compresses at once.
+const lanes = 8
+
+var blockMulti = blockMultiGeneric
@@ -0,0 +1,161 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha256
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"testing"
+)
+
+func batchInputs(n int) [][]byte {
+	sizes := []int{0, 1, 3, 55, 56, 57, 63, 64, 65, 119, 120, 128, 200, 1000}
+	buf := make([]byte, 1000)
+	rand.Read(buf)
+	inputs := make([][]byte, n)
+	for i := range inputs {
+		inputs[i] = buf[i%7 : i%7+sizes[i%len(sizes)]%(len(buf)-7)]
+	}
+	return inputs
+}
+
+func TestSum256Batch(t *testing.T) {
+	for _, n := range []int{0, 1, 3, 4, 7, 8, 9, 15, 16, 17, 100} {
+		inputs := batchInputs(n)
+		sums := Sum256Batch(inputs)
+		if len(sums) != n {
+			t.Fatalf("Sum256Batch returned %d checksums for %d inputs", len(sums), n)
+		}
+		for i, in := range inputs {
+			if want := Sum256(in); sums[i] != want {
+				t.Errorf("Sum256Batch(%d inputs)[%d] (len %d) = %x, want %x", n, i, len(in), sums[i], want)
+			}
+		}
+
+		// Exercise the lane scheduler even where Sum256Batch does not use it.
+		sums = make([][Size]byte, n)
+		sumMulti(inputs, sums)
+		for i, in := range inputs {
+			if want := Sum256(in); sums[i] != want {
+				t.Errorf("sumMulti(%d inputs)[%d] (len %d) = %x, want %x", n, i, len(in), sums[i], want)
+			}
+		}
+	}
+}
+
+func TestSum256BatchImplementations(t *testing.T) {
+	defer SetImplementation("auto")
+	inputs := batchInputs(50)
+	for _, impl := range implementations() {
+		if err := SetImplementation(impl); err != nil {
+			t.Fatalf("SetImplementation(%q): %v", impl, err)
+		}
+		if (impl == "avx2" || impl == "avx512") && !useMulti {
+			t.Errorf("Sum256Batch does not use multi-buffer hashing with the %s implementation", impl)
+		}
+		sums := Sum256Batch(inputs)
+		for i, in := range inputs {
+			if want := sum256Generic(in); sums[i] != want {
+				t.Errorf("%s: Sum256Batch[%d] (len %d) = %x, want %x", impl, i, len(in), sums[i], want)
+			}
+		}
+	}
+}
+
+// sum256Generic returns the SHA256 checksum of in computed by blockGeneric.
+func sum256Generic(in []byte) [Size]byte {
+	var d digest
+	d.Reset()
+	n := len(in) &^ (chunk - 1)
+	blockGeneric(&d, in[:n])
+	var buf [2 * chunk]byte
+	blockGeneric(&d, padBlocks(&buf, in[n:], uint64(len(in))))
+	var sum [Size]byte
+	for i := range d.h {
+		binary.BigEndian.PutUint32(sum[4*i:], d.h[i])
+	}
+	return sum
+}
+
+// Tests that blockMultiGeneric and blockMulti (in assembly for amd64) match
+// for every implementation.
+func TestBlockMultiGeneric(t *testing.T) {
+	defer SetImplementation("auto")
+	var h [8][maxLanes]uint32
+	var w [16][maxLanes]uint32
+	for i := range h {
+		for j := range h[i] {
+			h[i][j] = uint32(i*maxLanes+j) * 0x9e3779b9
+		}
+	}
+	for i := range w {
+		for j := range w[i] {
+			w[i][j] = uint32(i*maxLanes+j) * 0x85ebca6b
+		}
+	}
+	for _, impl := range implementations() {
+		if err := SetImplementation(impl); err != nil {
+			t.Fatalf("SetImplementation(%q): %v", impl, err)
+		}
+		h1, w1 := h, w
+		hGen, wGen := h, w
+		blockMultiGeneric(&hGen, &wGen)
+		blockMulti(&h1, &w1)
+		if h1 != hGen {
+			t.Errorf("%s: blockMulti and blockMultiGeneric resulted in different states", impl)
+		}
+	}
+}
+
+var batchBench = batchInputs(1024)
+
+func BenchmarkSum256Batch(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Sum256Batch(batchBench)
+	}
+}
+
+func BenchmarkSum256Loop(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		sums := make([][Size]byte, len(batchBench))
+		for j, in := range batchBench {
+			sums[j] = Sum256(in)
+		}
+	}
+}
+
+func benchmarkBatchSize(b *testing.B, size int, batch bool) {
+	inputs := make([][]byte, 1024)
+	for i := range inputs {
+		inputs[i] = buf[:size]
+	}
+	b.SetBytes(int64(size * len(inputs)))
+	for i := 0; i < b.N; i++ {
+		if batch {
+			Sum256Batch(inputs)
+		} else {
+			for _, in := range inputs {
+				Sum256(in)
+			}
+		}
+	}
+}
+
+func BenchmarkSum256Batch32Bytes(b *testing.B) { benchmarkBatchSize(b, 32, true) }
+func BenchmarkSum256Loop32Bytes(b *testing.B)  { benchmarkBatchSize(b, 32, false) }
+func BenchmarkSum256Batch1K(b *testing.B)      { benchmarkBatchSize(b, 1024, true) }
+func BenchmarkSum256Loop1K(b *testing.B)       { benchmarkBatchSize(b, 1024, false) }
+
+func benchmarkBatchImplementation(b *testing.B, impl string) {
+	if err := SetImplementation(impl); err != nil {
+		b.Skip(err)
+	}
+	defer SetImplementation("auto")
+	benchmarkBatchSize(b, 64, true)
+}
+
+func BenchmarkSum256Batch64BytesShaNI(b *testing.B)  { benchmarkBatchImplementation(b, "shani") }
+func BenchmarkSum256Batch64BytesAVX2(b *testing.B)   { benchmarkBatchImplementation(b, "avx2") }
+func BenchmarkSum256Batch64BytesAVX512(b *testing.B) { benchmarkBatchImplementation(b, "avx512") }
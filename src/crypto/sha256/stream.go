@@ -0,0 +1,327 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha256
+
+import (
+	"context"
+	"crypto/internal/hashio"
+	"errors"
+	"io"
+	"time"
+)
+
+// bufSize is the size of the buffer the streaming helpers read into.
+const bufSize = hashio.BufSize
+
+// HashStats describes where the time went while hashing a reader.
+type HashStats struct {
+	// BytesRead is the total number of bytes read and hashed.
+	BytesRead int64
+
+	// Reads is the number of calls made to the reader's Read method,
+	// including the final call that returned io.EOF or an error.
+	Reads int
+
+	// ReadTime is the total time spent inside the reader's Read method.
+	ReadTime time.Duration
+
+	// HashTime is the total time spent hashing, including finalization.
+	HashTime time.Duration
+
+	// MaxRead is the largest number of bytes returned by a single Read.
+	MaxRead int
+}
+
+// Sum256WithStats returns the SHA256 checksum of the data read from r,
+// along with statistics that show whether hashing r was bound by I/O or
+// by CPU. On a read error other than io.EOF the zero checksum, the
+// statistics gathered so far and the error are returned.
+func Sum256WithStats(r io.Reader) (sum [Size]byte, stats HashStats, err error) {
+	d := new(digest)
+	d.Reset()
+	var s hashio.Stats
+	if err := hashio.ReadWithStats(r, d, &s); err != nil {
+		return sum, HashStats(s), err
+	}
+	start := time.Now()
+	sum = d.checkSum()
+	s.HashTime += time.Since(start)
+	return sum, HashStats(s), nil
+}
+
+// SumSplit reads r to EOF and returns the SHA256 checksum of each record in
+// it. Records are separated by delim, which is not part of the checksum.
+// A final record without a trailing delimiter is included; an empty input
+// has no records. Records are hashed as they are read, so arbitrarily long
+// records do not need to fit in memory. On a read error other than io.EOF
+// the checksums of the records completed so far are returned with the
+// error.
+func SumSplit(r io.Reader, delim byte) (digests [][Size]byte, err error) {
+	d := new(digest)
+	d.Reset()
+	err = hashio.Split(r, delim, d, func() {
+		digests = append(digests, d.checkSum())
+		d.Reset()
+	})
+	return digests, err
+}
+
+// Sum256Reader returns the SHA256 checksum of the data read from r up to
+// io.EOF and the number of bytes read. r is read into a buffer of 32 KiB,
+// a multiple of BlockSize, so full reads are hashed without an extra
+// copy. On a read error other than io.EOF the zero checksum is returned
+// with the number of bytes read before the error.
+func Sum256Reader(r io.Reader) (sum [Size]byte, n int64, err error) {
+	var d digest
+	d.Reset()
+	buf := make([]byte, bufSize)
+	for {
+		nr, err := r.Read(buf)
+		d.Write(buf[:nr])
+		n += int64(nr)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return sum, n, err
+		}
+	}
+	return d.checkSum(), n, nil
+}
+
+// fileBufSize is the largest buffer Sum256File reads into.
+const fileBufSize = hashio.FileBufSize
+
+// Sum256File returns the SHA256 checksum of the contents of the named
+// file and its size in bytes. The file is read in chunks of up to 128 KiB,
+// a multiple of BlockSize; smaller regular files get a buffer just large
+// enough to hold them. The file is always closed before Sum256File
+// returns. The first error from opening, reading or closing the file is
+// returned.
+func Sum256File(path string) (sum [Size]byte, size int64, err error) {
+	d := new(digest)
+	d.Reset()
+	if size, err = hashio.ReadFile(path, d); err != nil {
+		return sum, size, err
+	}
+	return d.checkSum(), size, nil
+}
+
+// ErrChecksumMismatch is returned by Verifier.Verify and by the reader
+// returned by NewVerifyReader when the data does not have the expected
+// checksum.
+var ErrChecksumMismatch = errors.New("crypto/sha256: checksum mismatch")
+
+// A Verifier checks that the data written to it has an expected SHA256
+// checksum. The checksum can only be compared once all the data has been
+// written, so callers must signal the end of the stream by calling Verify
+// before trusting any of it.
+type Verifier struct {
+	d        digest
+	expected [Size]byte
+	verified bool // Verify has been called
+	valid    bool // the data matched expected
+}
+
+// NewVerifier returns a Verifier for data whose SHA256 checksum is
+// expected.
+func NewVerifier(expected [Size]byte) *Verifier {
+	v := &Verifier{expected: expected}
+	v.d.Reset()
+	return v
+}
+
+// Write adds p to the data being verified. It returns an error if Verify
+// has already been called.
+func (v *Verifier) Write(p []byte) (int, error) {
+	if v.verified {
+		return 0, errors.New("crypto/sha256: Write after Verify")
+	}
+	return v.d.Write(p)
+}
+
+// BytesWritten returns the number of bytes written so far.
+func (v *Verifier) BytesWritten() uint64 {
+	return v.d.len
+}
+
+// Verify marks the end of the data and compares its checksum with the
+// expected one in constant time. It returns ErrChecksumMismatch if they
+// differ. Subsequent calls return the same result.
+func (v *Verifier) Verify() error {
+	if !v.verified {
+		v.verified = true
+		v.valid = Equal(v.d.checkSum(), v.expected)
+	}
+	if !v.valid {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
+
+// Valid reports whether Verify has been called and the data matched the
+// expected checksum.
+func (v *Verifier) Valid() bool {
+	return v.verified && v.valid
+}
+
+// NewVerifyReader returns a reader that reads from r and checks that the
+// data has the SHA256 checksum expected. When r reports io.EOF, the reader
+// returns ErrChecksumMismatch instead if the checksum differs, so that an
+// io.Copy from it fails rather than completing with corrupt data. Data is
+// passed through as it is read and must not be trusted before the reader
+// has returned io.EOF.
+func NewVerifyReader(r io.Reader, expected [Size]byte) io.Reader {
+	v := &verifyReader{r: r, expected: expected}
+	v.d.Reset()
+	return v
+}
+
+type verifyReader struct {
+	r        io.Reader
+	d        digest
+	expected [Size]byte
+	err      error // result of the check, once r has returned io.EOF
+}
+
+func (v *verifyReader) Read(p []byte) (int, error) {
+	if v.err != nil {
+		return 0, v.err
+	}
+	n, err := v.r.Read(p)
+	v.d.Write(p[:n])
+	if err == io.EOF {
+		if !Equal(v.d.checkSum(), v.expected) {
+			err = ErrChecksumMismatch
+		}
+		v.err = err
+	}
+	return n, err
+}
+
+// Sum256Range returns the SHA256 checksum of the n bytes of r starting at
+// offset off. The range is read with ReadAt in multiples of BlockSize. If
+// fewer than n bytes are available, the error is io.ErrUnexpectedEOF. A
+// zero n yields the checksum of the empty input without reading r. A
+// negative off or n, or a range ending past the largest int64 offset, is
+// rejected without reading r.
+func Sum256Range(r io.ReaderAt, off, n int64) ([Size]byte, error) {
+	if off < 0 || n < 0 || off+n < off {
+		return [Size]byte{}, errors.New("crypto/sha256: invalid range")
+	}
+	var d digest
+	d.Reset()
+	size := int64(bufSize)
+	if n < size {
+		size = n
+	}
+	buf := make([]byte, size)
+	for n > 0 {
+		p := buf
+		if int64(len(p)) > n {
+			p = p[:n]
+		}
+		k, err := r.ReadAt(p, off)
+		d.Write(p[:k])
+		off += int64(k)
+		n -= int64(k)
+		if k == len(p) {
+			// ReadAt may report io.EOF along with the last bytes.
+			continue
+		}
+		if err == io.EOF || err == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		return [Size]byte{}, err
+	}
+	return d.checkSum(), nil
+}
+
+// TeeSum256 copies src to dst until EOF on src or an error, like io.Copy,
+// and returns the SHA256 checksum of the data copied along with the number
+// of bytes copied. On a read or write error the zero checksum is returned
+// with the number of bytes successfully written to dst before the error.
+func TeeSum256(dst io.Writer, src io.Reader) (sum [Size]byte, written int64, err error) {
+	var d digest
+	d.Reset()
+	buf := make([]byte, bufSize)
+	for {
+		nr, er := src.Read(buf)
+		if nr > 0 {
+			nw, ew := dst.Write(buf[:nr])
+			if nw < 0 || nr < nw {
+				nw = 0
+				if ew == nil {
+					ew = errors.New("crypto/sha256: invalid write result")
+				}
+			}
+			d.Write(buf[:nw])
+			written += int64(nw)
+			if ew == nil && nw != nr {
+				ew = io.ErrShortWrite
+			}
+			if ew != nil {
+				return sum, written, ew
+			}
+		}
+		if er == io.EOF {
+			break
+		}
+		if er != nil {
+			return sum, written, er
+		}
+	}
+	return d.checkSum(), written, nil
+}
+
+// Sum256Context returns the SHA256 checksum of the data read from r. It
+// checks ctx before every read and, once ctx is done, stops reading and
+// returns ctx.Err(). Other read errors than io.EOF are returned as is.
+// Cancellation cannot interrupt a Read call that is already blocked.
+func Sum256Context(ctx context.Context, r io.Reader) ([Size]byte, error) {
+	var d digest
+	d.Reset()
+	buf := make([]byte, bufSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return [Size]byte{}, err
+		}
+		n, err := r.Read(buf)
+		d.Write(buf[:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return [Size]byte{}, err
+		}
+	}
+	return d.checkSum(), nil
+}
+
+// EqualReaders reads a and b to EOF and reports whether their data has the
+// same SHA256 checksum. The two streams are hashed concurrently, each in
+// reads of a multiple of BlockSize, without holding either in memory. If
+// reading either stream fails, EqualReaders returns false and the error,
+// preferring the error from a if both fail.
+func EqualReaders(a, b io.Reader) (bool, error) {
+	type result struct {
+		sum [Size]byte
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		sum, _, err := Sum256Reader(b)
+		done <- result{sum, err}
+	}()
+	sumA, _, errA := Sum256Reader(a)
+	rb := <-done
+	if errA != nil {
+		return false, errA
+	}
+	if rb.err != nil {
+		return false, rb.err
+	}
+	return Equal(sumA, rb.sum), nil
+}
@@ -0,0 +1,461 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha256
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestSum256WithStats(t *testing.T) {
+	data := make([]byte, 3*bufSize+17)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	want := Sum256(data)
+
+	sum, stats, err := Sum256WithStats(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Sum256WithStats: %v", err)
+	}
+	if sum != want {
+		t.Errorf("Sum256WithStats = %x, want %x", sum, want)
+	}
+	if stats.BytesRead != int64(len(data)) {
+		t.Errorf("BytesRead = %d, want %d", stats.BytesRead, len(data))
+	}
+	if stats.Reads != 5 {
+		t.Errorf("Reads = %d, want 5", stats.Reads)
+	}
+	if stats.MaxRead != bufSize {
+		t.Errorf("MaxRead = %d, want %d", stats.MaxRead, bufSize)
+	}
+
+	sum, stats, err = Sum256WithStats(iotest.OneByteReader(bytes.NewReader(data[:100])))
+	if err != nil {
+		t.Fatalf("Sum256WithStats: %v", err)
+	}
+	if want := Sum256(data[:100]); sum != want {
+		t.Errorf("Sum256WithStats = %x, want %x", sum, want)
+	}
+	if stats.Reads != 101 || stats.MaxRead != 1 {
+		t.Errorf("Reads, MaxRead = %d, %d, want 101, 1", stats.Reads, stats.MaxRead)
+	}
+
+	errRead := errors.New("read failed")
+	r := io.MultiReader(bytes.NewReader(data[:10]), iotest.ErrReader(errRead))
+	if _, stats, err = Sum256WithStats(r); err != errRead {
+		t.Errorf("Sum256WithStats error = %v, want %v", err, errRead)
+	}
+	if stats.BytesRead != 10 {
+		t.Errorf("BytesRead = %d, want 10", stats.BytesRead)
+	}
+}
+
+func TestSumSplit(t *testing.T) {
+	long := bytes.Repeat([]byte("x"), 2*bufSize+5)
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a\n", []string{"a"}},
+		{"a\nb", []string{"a", "b"}},
+		{"a\n\nb\n", []string{"a", "", "b"}},
+		{"\n", []string{""}},
+		{"a\n" + string(long) + "\nc", []string{"a", string(long), "c"}},
+	}
+	for _, tt := range tests {
+		for _, r := range []io.Reader{strings.NewReader(tt.in), iotest.HalfReader(strings.NewReader(tt.in))} {
+			digests, err := SumSplit(r, '\n')
+			if err != nil {
+				t.Fatalf("SumSplit: %v", err)
+			}
+			if len(digests) != len(tt.want) {
+				t.Fatalf("SumSplit(%.20q) returned %d digests, want %d", tt.in, len(digests), len(tt.want))
+			}
+			for i, rec := range tt.want {
+				if want := Sum256([]byte(rec)); digests[i] != want {
+					t.Errorf("SumSplit(%.20q)[%d] = %x, want %x", tt.in, i, digests[i], want)
+				}
+			}
+		}
+	}
+
+	errRead := errors.New("read failed")
+	r := io.MultiReader(strings.NewReader("a\x00b"), iotest.ErrReader(errRead))
+	digests, err := SumSplit(r, 0)
+	if err != errRead {
+		t.Errorf("SumSplit error = %v, want %v", err, errRead)
+	}
+	if len(digests) != 1 || digests[0] != Sum256([]byte("a")) {
+		t.Errorf("SumSplit returned %x before error, want checksum of %q", digests, "a")
+	}
+}
+
+func TestSum256Reader(t *testing.T) {
+	data := make([]byte, 2*bufSize+3*BlockSize+7)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	for _, n := range []int{0, 1, BlockSize, bufSize, len(data)} {
+		for _, r := range []io.Reader{
+			bytes.NewReader(data[:n]),
+			iotest.HalfReader(bytes.NewReader(data[:n])),
+			iotest.DataErrReader(bytes.NewReader(data[:n])),
+		} {
+			sum, nr, err := Sum256Reader(r)
+			if want := Sum256(data[:n]); sum != want || nr != int64(n) || err != nil {
+				t.Errorf("Sum256Reader of %d bytes = %x, %d, %v, want %x, %d, nil", n, sum, nr, err, want, n)
+			}
+		}
+	}
+
+	errRead := errors.New("read error")
+	r := io.MultiReader(bytes.NewReader(data[:100]), iotest.ErrReader(errRead))
+	if sum, n, err := Sum256Reader(r); sum != [Size]byte{} || n != 100 || err != errRead {
+		t.Errorf("Sum256Reader with a read error = %x, %d, %v, want zero, 100, %v", sum, n, err, errRead)
+	}
+}
+
+func TestSum256File(t *testing.T) {
+	dir := t.TempDir()
+
+	data := make([]byte, 3<<20+17)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	for _, n := range []int{0, 1, BlockSize - 1, BlockSize, fileBufSize - 1, fileBufSize, fileBufSize + 1, len(data)} {
+		path := filepath.Join(dir, fmt.Sprint("data", n))
+		if err := os.WriteFile(path, data[:n], 0666); err != nil {
+			t.Fatal(err)
+		}
+		sum, size, err := Sum256File(path)
+		if err != nil {
+			t.Fatalf("Sum256File of %d bytes: %v", n, err)
+		}
+		if want := Sum256(data[:n]); sum != want || size != int64(n) {
+			t.Errorf("Sum256File of %d bytes = %x, %d, want %x, %d", n, sum, size, want, n)
+		}
+	}
+
+	if _, _, err := Sum256File(filepath.Join(dir, "missing")); !os.IsNotExist(err) {
+		t.Errorf("Sum256File(missing) error = %v, want a not-exist error", err)
+	}
+	if _, _, err := Sum256File(dir); err == nil {
+		t.Error("Sum256File of a directory: no error when one was expected")
+	}
+}
+
+func BenchmarkSum256File(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "data")
+	data := make([]byte, 16<<20)
+	if err := os.WriteFile(path, data, 0666); err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		if _, _, err := Sum256File(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	data := make([]byte, 3*BlockSize+11)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	sum := Sum256(data)
+
+	flipped := append([]byte(nil), data...)
+	flipped[100] ^= 1
+
+	tests := []struct {
+		name string
+		data []byte
+		ok   bool
+	}{
+		{"match", data, true},
+		{"flipped", flipped, false},
+		{"truncated", data[:len(data)-1], false},
+	}
+	for _, tt := range tests {
+		v := NewVerifier(sum)
+		// Write in uneven pieces.
+		for p := tt.data; len(p) > 0; {
+			n := 7
+			if n > len(p) {
+				n = len(p)
+			}
+			if _, err := v.Write(p[:n]); err != nil {
+				t.Fatalf("%s: Write: %v", tt.name, err)
+			}
+			p = p[n:]
+		}
+		if v.Valid() {
+			t.Errorf("%s: Valid before Verify = true", tt.name)
+		}
+		if n := v.BytesWritten(); n != uint64(len(tt.data)) {
+			t.Errorf("%s: BytesWritten = %d, want %d", tt.name, n, len(tt.data))
+		}
+		err := v.Verify()
+		if (err == nil) != tt.ok || err != nil && err != ErrChecksumMismatch {
+			t.Errorf("%s: Verify = %v, want ok = %v", tt.name, err, tt.ok)
+		}
+		if v.Valid() != tt.ok {
+			t.Errorf("%s: Valid = %v, want %v", tt.name, v.Valid(), tt.ok)
+		}
+		if err2 := v.Verify(); (err2 == nil) != tt.ok {
+			t.Errorf("%s: second Verify = %v, want ok = %v", tt.name, err2, tt.ok)
+		}
+		if _, err := v.Write([]byte("x")); err == nil {
+			t.Errorf("%s: Write after Verify: no error when one was expected", tt.name)
+		}
+	}
+}
+
+func TestSum256Range(t *testing.T) {
+	data := make([]byte, 2*bufSize+300)
+	for i := range data {
+		data[i] = byte(i * 13)
+	}
+	r := bytes.NewReader(data)
+	tests := []struct{ off, n int64 }{
+		{0, 0},
+		{5, 0},
+		{0, 64},
+		{3, 61},
+		{10, 117},
+		{63, 130},
+		{bufSize - 5, bufSize + 17},
+		{0, int64(len(data))},
+		{int64(len(data)) - 10, 10},
+	}
+	for _, tt := range tests {
+		sum, err := Sum256Range(r, tt.off, tt.n)
+		if err != nil {
+			t.Errorf("Sum256Range(%d, %d): %v", tt.off, tt.n, err)
+			continue
+		}
+		if want := Sum256(data[tt.off : tt.off+tt.n]); sum != want {
+			t.Errorf("Sum256Range(%d, %d) = %x, want %x", tt.off, tt.n, sum, want)
+		}
+	}
+
+	for _, tt := range []struct{ off, n int64 }{
+		{int64(len(data)) - 10, 11},
+		{int64(len(data)), 1},
+		{int64(len(data)) + 100, 1},
+	} {
+		if _, err := Sum256Range(r, tt.off, tt.n); err != io.ErrUnexpectedEOF {
+			t.Errorf("Sum256Range(%d, %d) error = %v, want %v", tt.off, tt.n, err, io.ErrUnexpectedEOF)
+		}
+	}
+	if _, err := Sum256Range(r, -1, 1); err == nil {
+		t.Error("Sum256Range with negative offset: no error when one was expected")
+	}
+	if _, err := Sum256Range(r, 0, -1); err == nil {
+		t.Error("Sum256Range with negative length: no error when one was expected")
+	}
+	if _, err := Sum256Range(r, 1<<62, 1<<62); err == nil || err == io.ErrUnexpectedEOF {
+		t.Errorf("Sum256Range with overflowing range: error = %v, want invalid range", err)
+	}
+}
+
+// limitedWriter accepts n bytes and then fails.
+type limitedWriter struct {
+	bytes.Buffer
+	n int
+}
+
+var errWriteLimit = errors.New("write limit reached")
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		n, _ := w.Buffer.Write(p[:w.n])
+		w.n = 0
+		return n, errWriteLimit
+	}
+	w.n -= len(p)
+	return w.Buffer.Write(p)
+}
+
+func TestTeeSum256(t *testing.T) {
+	data := make([]byte, 2*bufSize+100)
+	for i := range data {
+		data[i] = byte(i * 3)
+	}
+	for _, n := range []int{0, 1, 64, 1000, len(data)} {
+		var dst bytes.Buffer
+		sum, written, err := TeeSum256(&dst, iotest.HalfReader(bytes.NewReader(data[:n])))
+		if err != nil {
+			t.Fatalf("TeeSum256(%d bytes): %v", n, err)
+		}
+		if written != int64(n) || !bytes.Equal(dst.Bytes(), data[:n]) {
+			t.Errorf("TeeSum256(%d bytes) copied %d bytes, %d in dst", n, written, dst.Len())
+		}
+		if want := Sum256(data[:n]); sum != want {
+			t.Errorf("TeeSum256(%d bytes) = %x, want %x", n, sum, want)
+		}
+	}
+
+	// Read error.
+	errRead := errors.New("read failed")
+	r := io.MultiReader(bytes.NewReader(data[:100]), iotest.ErrReader(errRead))
+	var dst bytes.Buffer
+	_, written, err := TeeSum256(&dst, r)
+	if err != errRead || written != 100 || dst.Len() != 100 {
+		t.Errorf("TeeSum256 with read error = %d, %v, want 100, %v", written, err, errRead)
+	}
+
+	// Write error.
+	w := &limitedWriter{n: 150}
+	_, written, err = TeeSum256(w, bytes.NewReader(data))
+	if err != errWriteLimit || written != 150 || w.Len() != 150 {
+		t.Errorf("TeeSum256 with write error = %d, %v, want 150, %v", written, err, errWriteLimit)
+	}
+}
+
+// cancelingReader cancels a context after a number of reads.
+type cancelingReader struct {
+	r      io.Reader
+	reads  int
+	after  int
+	cancel context.CancelFunc
+}
+
+func (c *cancelingReader) Read(p []byte) (int, error) {
+	c.reads++
+	if c.reads == c.after {
+		c.cancel()
+	}
+	return c.r.Read(p)
+}
+
+func TestSum256Context(t *testing.T) {
+	data := make([]byte, 5*bufSize+3)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	sum, err := Sum256Context(context.Background(), bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Sum256Context: %v", err)
+	}
+	if want := Sum256(data); sum != want {
+		t.Errorf("Sum256Context = %x, want %x", sum, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := bytes.NewReader(data)
+	if _, err := Sum256Context(ctx, r); err != context.Canceled {
+		t.Errorf("Sum256Context with canceled context error = %v, want %v", err, context.Canceled)
+	}
+	if r.Len() != len(data) {
+		t.Errorf("Sum256Context with canceled context read %d bytes", len(data)-r.Len())
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	r = bytes.NewReader(data)
+	cr := &cancelingReader{r: r, after: 2, cancel: cancel}
+	if _, err := Sum256Context(ctx, cr); err != context.Canceled {
+		t.Errorf("Sum256Context canceled mid-stream error = %v, want %v", err, context.Canceled)
+	}
+	if cr.reads != 2 || r.Len() == 0 {
+		t.Errorf("Sum256Context canceled mid-stream made %d reads, %d bytes left", cr.reads, r.Len())
+	}
+}
+
+func TestVerifyReader(t *testing.T) {
+	data := make([]byte, 3*BlockSize+11)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	sum := Sum256(data)
+
+	var dst bytes.Buffer
+	n, err := io.Copy(&dst, NewVerifyReader(iotest.OneByteReader(bytes.NewReader(data)), sum))
+	if err != nil || n != int64(len(data)) || !bytes.Equal(dst.Bytes(), data) {
+		t.Errorf("copy from matching VerifyReader = %d, %v", n, err)
+	}
+
+	flipped := append([]byte(nil), data...)
+	flipped[len(flipped)-1] ^= 1
+	for _, in := range [][]byte{flipped, data[:len(data)-1], data[:0]} {
+		dst.Reset()
+		r := NewVerifyReader(iotest.HalfReader(bytes.NewReader(in)), sum)
+		n, err := io.Copy(&dst, r)
+		if err != ErrChecksumMismatch {
+			t.Errorf("copy of %d mismatching bytes error = %v, want %v", len(in), err, ErrChecksumMismatch)
+		}
+		if n != int64(len(in)) || !bytes.Equal(dst.Bytes(), in) {
+			t.Errorf("copy of %d mismatching bytes copied %d bytes", len(in), n)
+		}
+		if _, err := r.Read(make([]byte, 1)); err != ErrChecksumMismatch {
+			t.Errorf("Read after mismatch error = %v, want %v", err, ErrChecksumMismatch)
+		}
+	}
+
+	if err := iotest.TestReader(NewVerifyReader(bytes.NewReader(data), sum), data); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEqualReaders(t *testing.T) {
+	data := make([]byte, bufSize+3*BlockSize+5)
+	for i := range data {
+		data[i] = byte(i * 13)
+	}
+	flipped := append([]byte(nil), data...)
+	flipped[bufSize] ^= 1
+
+	tests := []struct {
+		a, b []byte
+		want bool
+	}{
+		{data, data, true},
+		{nil, nil, true},
+		{data, flipped, false},
+		{data, data[:len(data)-1], false},
+		{data[:0], data[:1], false},
+	}
+	for _, tt := range tests {
+		// Differently sized reads must not matter.
+		a := iotest.HalfReader(bytes.NewReader(tt.a))
+		b := iotest.OneByteReader(bytes.NewReader(tt.b))
+		if eq, err := EqualReaders(a, b); eq != tt.want || err != nil {
+			t.Errorf("EqualReaders of %d and %d bytes = %v, %v, want %v, nil", len(tt.a), len(tt.b), eq, err, tt.want)
+		}
+	}
+
+	errA := errors.New("read error in a")
+	errB := errors.New("read error in b")
+	failing := func(err error) io.Reader {
+		return io.MultiReader(bytes.NewReader(data[:100]), iotest.ErrReader(err))
+	}
+	for _, tt := range []struct {
+		a, b io.Reader
+		err  error
+	}{
+		{failing(errA), bytes.NewReader(data[:100]), errA},
+		{bytes.NewReader(data[:100]), failing(errB), errB},
+		{failing(errA), failing(errB), errA},
+	} {
+		if eq, err := EqualReaders(tt.a, tt.b); eq || err != tt.err {
+			t.Errorf("EqualReaders with a failing reader = %v, %v, want false, %v", eq, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha256
+
+import (
+	"errors"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Sum256Tree returns a two-level tree hash of data that can be computed
+// on several cores. data is split into consecutive chunks of chunkSize
+// bytes, the last of which may be shorter, and the result is
+//
+//	SHA256(SHA256(chunk 0) || SHA256(chunk 1) || ... || SHA256(chunk n-1))
+//
+// An empty data has no chunks and hashes to SHA256 of the empty string.
+//
+// The tree hash is not the SHA256 checksum of data and is not compatible
+// with any standard; it depends on chunkSize, so both sides must agree on
+// it. For a given chunkSize the result is deterministic and does not
+// depend on workers, which is the number of goroutines used to hash the
+// chunks. If workers is zero or negative, runtime.GOMAXPROCS(0) is used.
+//
+// Sum256Tree panics if chunkSize is not positive.
+func Sum256Tree(data []byte, chunkSize int, workers int) [Size]byte {
+	if chunkSize <= 0 {
+		panic("crypto/sha256: invalid tree chunk size")
+	}
+	// Written so that it cannot overflow for any positive chunkSize.
+	n := len(data) / chunkSize
+	if len(data)%chunkSize != 0 {
+		n++
+	}
+	sum, _ := treeHash(n, workers, func(_, i int) ([Size]byte, error) {
+		chunk := data[i*chunkSize:]
+		if len(chunk) > chunkSize {
+			chunk = chunk[:chunkSize]
+		}
+		return Sum256(chunk), nil
+	})
+	return sum
+}
+
+// Sum256TreeFile returns the tree hash of the contents of the named file,
+// the same as Sum256Tree of the contents with the same chunkSize. Each of
+// the workers reads its chunks with ReadAt into a buffer of its own, so
+// the file is never held in memory as a whole. The file must be a regular
+// file, since its size is taken up front; otherwise the error is an
+// *os.PathError wrapping ErrNotRegular. If the file shrinks while it is
+// hashed, the error is io.ErrUnexpectedEOF. Errors from opening, reading
+// or closing the file are returned unchanged, so errors.Is can be used
+// on them. After the first error the workers stop, and that error, not
+// any that other workers hit while stopping, is returned. A file with
+// more chunks than the leaf checksums can be held for in memory yields
+// ErrTooManyLeaves.
+//
+// Sum256TreeFile panics if chunkSize is not positive.
+func Sum256TreeFile(path string, chunkSize int, workers int) ([Size]byte, error) {
+	if chunkSize <= 0 {
+		panic("crypto/sha256: invalid tree chunk size")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return [Size]byte{}, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return [Size]byte{}, err
+	}
+	if !fi.Mode().IsRegular() {
+		f.Close()
+		return [Size]byte{}, &os.PathError{Op: "read", Path: path, Err: ErrNotRegular}
+	}
+	size := fi.Size()
+	n, ok := treeLeaves(size, chunkSize)
+	if !ok {
+		f.Close()
+		return [Size]byte{}, ErrTooManyLeaves
+	}
+	leafBuf := chunkSize
+	if int64(leafBuf) > size {
+		leafBuf = int(size)
+	}
+	workers = treeWorkers(n, workers)
+	bufs := make([][]byte, workers)
+	sum, err := treeHash(n, workers, func(w, i int) ([Size]byte, error) {
+		if bufs[w] == nil {
+			bufs[w] = make([]byte, leafBuf)
+		}
+		off := int64(i) * int64(chunkSize)
+		p := bufs[w]
+		if rest := size - off; rest < int64(len(p)) {
+			p = p[:rest]
+		}
+		k, err := f.ReadAt(p, off)
+		if k < len(p) {
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return [Size]byte{}, err
+		}
+		return Sum256(p), nil
+	})
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return [Size]byte{}, err
+	}
+	return sum, nil
+}
+
+// ErrNotRegular is returned, wrapped in an *os.PathError, by
+// Sum256TreeFile when the named file is not a regular file.
+var ErrNotRegular = errors.New("crypto/sha256: not a regular file")
+
+// ErrTooManyLeaves is returned by Sum256TreeFile when the file has so
+// many chunks that their checksums would not fit in memory, which can
+// happen on 32-bit platforms with a small chunk size.
+var ErrTooManyLeaves = errors.New("crypto/sha256: too many tree leaves")
+
+// maxInt is the largest value of an int.
+const maxInt = int(^uint(0) >> 1)
+
+// treeLeaves returns the number of chunks of chunkSize bytes in size
+// bytes. It reports false if the checksums of that many chunks would not
+// fit in a byte slice.
+func treeLeaves(size int64, chunkSize int) (int, bool) {
+	n := size / int64(chunkSize)
+	if size%int64(chunkSize) != 0 {
+		n++
+	}
+	if uint64(n) > uint64(maxInt/Size) {
+		return 0, false
+	}
+	return int(n), true
+}
+
+// treeWorkers returns the number of goroutines to hash n leaves with, given
+// the workers argument of Sum256Tree.
+func treeWorkers(n, workers int) int {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > n {
+		workers = n
+	}
+	return workers
+}
+
+// treeHash returns the SHA256 checksum of the concatenation of the n leaf
+// checksums returned by leaf, which is called concurrently from up to
+// workers goroutines, numbered from 0, with the index of each leaf. Once
+// leaf returns an error no further leaves are started, and the first
+// error to occur is returned.
+func treeHash(n, workers int, leaf func(w, i int) ([Size]byte, error)) ([Size]byte, error) {
+	workers = treeWorkers(n, workers)
+	leaves := make([]byte, n*Size)
+	var (
+		failed   uint32
+		errOnce  sync.Once
+		firstErr error
+	)
+	hash := func(w int) {
+		for i := w; i < n && atomic.LoadUint32(&failed) == 0; i += workers {
+			sum, err := leaf(w, i)
+			if err != nil {
+				errOnce.Do(func() { firstErr = err })
+				atomic.StoreUint32(&failed, 1)
+				return
+			}
+			copy(leaves[i*Size:], sum[:])
+		}
+	}
+	if workers <= 1 {
+		hash(0)
+	} else {
+		var wg sync.WaitGroup
+		wg.Add(workers)
+		for w := 0; w < workers; w++ {
+			go func(w int) {
+				defer wg.Done()
+				hash(w)
+			}(w)
+		}
+		wg.Wait()
+	}
+	if firstErr != nil {
+		return [Size]byte{}, firstErr
+	}
+	return Sum256(leaves), nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha256
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var treeTests = []struct {
+	n, chunkSize int
+	out          string
+}{
+	{0, 1024, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+	{1, 1024, "1406e05881e299367766d313e26c05564ec91bf721d31726bd6e46e60689539a"},
+	{1024, 1024, "0b7db34d6857ac6d1a3e99833ca692a1112eb0d97ba041c8311cc3e265377ffc"},
+	{1025, 1024, "bdaf4f16f2106d5807886f560e865c02b811213b765c3398b19b149468124b46"},
+	{10000, 1024, "ccff2ed2ccbe199d73bc97129ba0b3b4c75a2ff64a38be076e21819a2f783ae2"},
+	{10000, 64, "8736bf4dee5e85c762a16327ffb69f76b620c46ae016ab4a44dd1393032eb2f4"},
+	{10000, 10000, "5c386a1b9706f2ef5f8f41f61c284b34282eb1bf39e6341d86bca456eab511f2"},
+}
+
+func TestSum256Tree(t *testing.T) {
+	data := make([]byte, 10000)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	for _, tt := range treeTests {
+		for _, workers := range []int{0, 1, 2, 3, 16, 1000} {
+			sum := Sum256Tree(data[:tt.n], tt.chunkSize, workers)
+			if s := fmt.Sprintf("%x", sum); s != tt.out {
+				t.Errorf("Sum256Tree(%d bytes, %d, %d) = %s want %s", tt.n, tt.chunkSize, workers, s, tt.out)
+			}
+		}
+	}
+}
+
+func TestSum256TreeInvalidChunkSize(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Sum256Tree with chunk size 0 did not panic")
+		}
+	}()
+	Sum256Tree(nil, 0, 1)
+}
+
+func TestSum256TreeLargeChunkSize(t *testing.T) {
+	data := []byte("abc")
+	want := Sum256Tree(data, len(data), 1)
+	for _, chunkSize := range []int{maxInt, maxInt - 1, maxInt / 2} {
+		if got := Sum256Tree(data, chunkSize, 0); got != want {
+			t.Errorf("Sum256Tree(%q, %d) = %x want %x", data, chunkSize, got, want)
+		}
+	}
+}
+
+func BenchmarkSum256Tree(b *testing.B) {
+	data := make([]byte, 16<<20)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		Sum256Tree(data, 1<<20, 0)
+	}
+}
+
+func TestSum256TreeFile(t *testing.T) {
+	dir := t.TempDir()
+	data := make([]byte, 10000)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	for _, tt := range treeTests {
+		path := filepath.Join(dir, fmt.Sprint("data", tt.n))
+		if err := os.WriteFile(path, data[:tt.n], 0666); err != nil {
+			t.Fatal(err)
+		}
+		for _, workers := range []int{0, 1, 3, 1000} {
+			sum, err := Sum256TreeFile(path, tt.chunkSize, workers)
+			if err != nil {
+				t.Fatalf("Sum256TreeFile(%d bytes, %d, %d): %v", tt.n, tt.chunkSize, workers, err)
+			}
+			if s := fmt.Sprintf("%x", sum); s != tt.out {
+				t.Errorf("Sum256TreeFile(%d bytes, %d, %d) = %s want %s", tt.n, tt.chunkSize, workers, s, tt.out)
+			}
+		}
+	}
+
+	if _, err := Sum256TreeFile(filepath.Join(dir, "missing"), 1024, 0); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Sum256TreeFile(missing) error = %v, want a not-exist error", err)
+	}
+	var pe *fs.PathError
+	if _, err := Sum256TreeFile(dir, 1024, 0); !errors.Is(err, ErrNotRegular) || !errors.As(err, &pe) {
+		t.Errorf("Sum256TreeFile of a directory: error = %v, want a *PathError wrapping ErrNotRegular", err)
+	}
+
+	want, err := Sum256TreeFile(filepath.Join(dir, "data1025"), 1025, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Sum256TreeFile(filepath.Join(dir, "data1025"), maxInt, 0); err != nil || got != want {
+		t.Errorf("Sum256TreeFile(1025 bytes, %d) = %x, %v want %x", maxInt, got, err, want)
+	}
+}
+
+func TestTreeLeaves(t *testing.T) {
+	for _, tt := range []struct {
+		size      int64
+		chunkSize int
+		n         int
+		ok        bool
+	}{
+		{0, 1, 0, true},
+		{1, 1024, 1, true},
+		{1025, 1024, 2, true},
+		{int64(maxInt / Size), 1, maxInt / Size, true},
+		{int64(maxInt/Size) + 1, 1, 0, false},
+		{1<<63 - 1, 1, 0, false},
+	} {
+		n, ok := treeLeaves(tt.size, tt.chunkSize)
+		if n != tt.n || ok != tt.ok {
+			t.Errorf("treeLeaves(%d, %d) = %d, %v, want %d, %v", tt.size, tt.chunkSize, n, ok, tt.n, tt.ok)
+		}
+	}
+}
+
+func TestTreeHashFirstError(t *testing.T) {
+	// Three workers hash leaves {0, 3}, {1, 4} and {2, 5}. Leaf 1 fails
+	// first and leaf 0 only after that, so the error of the lower-numbered
+	// leaf must not win, and the worker finishing leaf 2 must not go on to
+	// leaf 5.
+	err0, err1 := errors.New("leaf 0"), errors.New("leaf 1")
+	failed := make(chan bool)
+	calls := make([]int, 6)
+	_, err := treeHash(len(calls), 3, func(w, i int) ([Size]byte, error) {
+		calls[i]++
+		switch i {
+		case 0:
+			<-failed
+			return [Size]byte{}, err0
+		case 1:
+			close(failed)
+			return [Size]byte{}, err1
+		case 2:
+			<-failed
+		}
+		return [Size]byte{}, nil
+	})
+	if err != err1 {
+		t.Errorf("treeHash error = %v, want %v", err, err1)
+	}
+	if calls[5] != 0 {
+		t.Error("treeHash started a leaf after an error")
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha512
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+)
+
+// selfTests are known-answer tests, mostly from FIPS 180-2 and the
+// examples NIST publishes for SHA-512/224 and SHA-512/256. The message is
+// in repeated count times.
+var selfTests = []struct {
+	name     string
+	function crypto.Hash
+	in       string
+	count    int
+	out      string
+}{
+	{
+		"SHA-512 abc", crypto.SHA512, "abc", 1,
+		"\xdd\xaf\x35\xa1\x93\x61\x7a\xba\xcc\x41\x73\x49\xae\x20\x41\x31" +
+			"\x12\xe6\xfa\x4e\x89\xa9\x7e\xa2\x0a\x9e\xee\xe6\x4b\x55\xd3\x9a" +
+			"\x21\x92\x99\x2a\x27\x4f\xc1\xa8\x36\xba\x3c\x23\xa3\xfe\xeb\xbd" +
+			"\x45\x4d\x44\x23\x64\x3c\xe8\x0e\x2a\x9a\xc9\x4f\xa5\x4c\xa4\x9f",
+	},
+	{
+		"SHA-512 empty string", crypto.SHA512, "", 1,
+		"\xcf\x83\xe1\x35\x7e\xef\xb8\xbd\xf1\x54\x28\x50\xd6\x6d\x80\x07" +
+			"\xd6\x20\xe4\x05\x0b\x57\x15\xdc\x83\xf4\xa9\x21\xd3\x6c\xe9\xce" +
+			"\x47\xd0\xd1\x3c\x5d\x85\xf2\xb0\xff\x83\x18\xd2\x87\x7e\xec\x2f" +
+			"\x63\xb9\x31\xbd\x47\x41\x7a\x81\xa5\x38\x32\x7a\xf9\x27\xda\x3e",
+	},
+	{
+		"SHA-512 896-bit message", crypto.SHA512, "abcdefghbcdefghicdefghijdefghijkefghijklfghijklmghijklmnhijklmnoijklmnopjklmnopqklmnopqrlmnopqrsmnopqrstnopqrstu", 1,
+		"\x8e\x95\x9b\x75\xda\xe3\x13\xda\x8c\xf4\xf7\x28\x14\xfc\x14\x3f" +
+			"\x8f\x77\x79\xc6\xeb\x9f\x7f\xa1\x72\x99\xae\xad\xb6\x88\x90\x18" +
+			"\x50\x1d\x28\x9e\x49\x00\xf7\xe4\x33\x1b\x99\xde\xc4\xb5\x43\x3a" +
+			"\xc7\xd3\x29\xee\xb6\xdd\x26\x54\x5e\x96\xe5\x5b\x87\x4b\xe9\x09",
+	},
+	{
+		"SHA-512 one million a", crypto.SHA512, "a", 1000000,
+		"\xe7\x18\x48\x3d\x0c\xe7\x69\x64\x4e\x2e\x42\xc7\xbc\x15\xb4\x63" +
+			"\x8e\x1f\x98\xb1\x3b\x20\x44\x28\x56\x32\xa8\x03\xaf\xa9\x73\xeb" +
+			"\xde\x0f\xf2\x44\x87\x7e\xa6\x0a\x4c\xb0\x43\x2c\xe5\x77\xc3\x1b" +
+			"\xeb\x00\x9c\x5c\x2c\x49\xaa\x2e\x4e\xad\xb2\x17\xad\x8c\xc0\x9b",
+	},
+	{
+		"SHA-384 abc", crypto.SHA384, "abc", 1,
+		"\xcb\x00\x75\x3f\x45\xa3\x5e\x8b\xb5\xa0\x3d\x69\x9a\xc6\x50\x07" +
+			"\x27\x2c\x32\xab\x0e\xde\xd1\x63\x1a\x8b\x60\x5a\x43\xff\x5b\xed" +
+			"\x80\x86\x07\x2b\xa1\xe7\xcc\x23\x58\xba\xec\xa1\x34\xc8\x25\xa7",
+	},
+	{
+		"SHA-384 896-bit message", crypto.SHA384, "abcdefghbcdefghicdefghijdefghijkefghijklfghijklmghijklmnhijklmnoijklmnopjklmnopqklmnopqrlmnopqrsmnopqrstnopqrstu", 1,
+		"\x09\x33\x0c\x33\xf7\x11\x47\xe8\x3d\x19\x2f\xc7\x82\xcd\x1b\x47" +
+			"\x53\x11\x1b\x17\x3b\x3b\x05\xd2\x2f\xa0\x80\x86\xe3\xb0\xf7\x12" +
+			"\xfc\xc7\xc7\x1a\x55\x7e\x2d\xb9\x66\xc3\xe9\xfa\x91\x74\x60\x39",
+	},
+	{
+		"SHA-512/224 abc", crypto.SHA512_224, "abc", 1,
+		"\x46\x34\x27\x0f\x70\x7b\x6a\x54\xda\xae\x75\x30\x46\x08\x42\xe2" +
+			"\x0e\x37\xed\x26\x5c\xee\xe9\xa4\x3e\x89\x24\xaa",
+	},
+	{
+		"SHA-512/224 896-bit message", crypto.SHA512_224, "abcdefghbcdefghicdefghijdefghijkefghijklfghijklmghijklmnhijklmnoijklmnopjklmnopqklmnopqrlmnopqrsmnopqrstnopqrstu", 1,
+		"\x23\xfe\xc5\xbb\x94\xd6\x0b\x23\x30\x81\x92\x64\x0b\x0c\x45\x33" +
+			"\x35\xd6\x64\x73\x4f\xe4\x0e\x72\x68\x67\x4a\xf9",
+	},
+	{
+		"SHA-512/256 abc", crypto.SHA512_256, "abc", 1,
+		"\x53\x04\x8e\x26\x81\x94\x1e\xf9\x9b\x2e\x29\xb7\x6b\x4c\x7d\xab" +
+			"\xe4\xc2\xd0\xc6\x34\xfc\x6d\x46\xe0\xe2\xf1\x31\x07\xe7\xaf\x23",
+	},
+	{
+		"SHA-512/256 896-bit message", crypto.SHA512_256, "abcdefghbcdefghicdefghijdefghijkefghijklfghijklmghijklmnhijklmnoijklmnopjklmnopqklmnopqrlmnopqrsmnopqrstnopqrstu", 1,
+		"\x39\x28\xe1\x84\xfb\x86\x90\xf8\x40\xda\x39\x88\x12\x1d\x31\xbe" +
+			"\x65\xcb\x9d\x3e\xf8\x3e\xe6\x14\x6f\xea\xc8\x61\xe1\x9b\x56\x3a",
+	},
+}
+
+// SelfTest runs known-answer tests, including multi-block messages,
+// through the same block function, assembly or not, that the hashes
+// returned by New, New384, New512_224 and New512_256 use. Each message is
+// hashed once with a single Write and once in short writes that straddle
+// block boundaries. SelfTest returns an error naming the first test that
+// fails; a non-nil result means that this build of the package computes
+// wrong checksums.
+func SelfTest() error {
+	for _, tt := range selfTests {
+		msg := bytes.Repeat([]byte(tt.in), tt.count)
+		for _, step := range []int{len(msg), 7} {
+			d := digest{function: tt.function}
+			d.Reset()
+			for p := msg; len(p) > 0; {
+				n := step
+				if n > len(p) {
+					n = len(p)
+				}
+				d.Write(p[:n])
+				p = p[n:]
+			}
+			sum := d.checkSum()
+			if string(sum[:len(tt.out)]) != tt.out {
+				return errors.New("crypto/sha512: self-test failed: " + tt.name)
+			}
+		}
+	}
+	return nil
+}
@@ -382,7 +382,7 @@ var depsRules = `
 	encoding/binary, golang.org/x/sys/cpu, hash
 	< crypto
 	< crypto/subtle
-	< crypto/internal/subtle
+	< crypto/internal/subtle, crypto/internal/hashenc, crypto/internal/hashio
 	< crypto/cipher
 	< crypto/aes, crypto/des, crypto/hmac, crypto/md5, crypto/rc4,
 	  crypto/sha1, crypto/sha256, crypto/sha512
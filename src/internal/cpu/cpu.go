@@ -21,7 +21,8 @@ var CacheLineSize uintptr = CacheLinePadSize
 
 // The booleans in X86 contain the correspondingly named cpuid feature bit.
 // HasAVX and HasAVX2 are only set if the OS does support XMM and YMM registers
-// in addition to the cpuid feature bit being set.
+// in addition to the cpuid feature bit being set. Likewise HasAVX512F is only
+// set if the OS also supports the opmask and ZMM registers.
 // The struct is padded to avoid false sharing.
 var X86 struct {
 	_            CacheLinePad
@@ -29,6 +30,7 @@ var X86 struct {
 	HasADX       bool
 	HasAVX       bool
 	HasAVX2      bool
+	HasAVX512F   bool
 	HasBMI1      bool
 	HasBMI2      bool
 	HasERMS      bool
@@ -36,6 +38,7 @@ var X86 struct {
 	HasOSXSAVE   bool
 	HasPCLMULQDQ bool
 	HasPOPCNT    bool
+	HasSHA       bool
 	HasSSE2      bool
 	HasSSE3      bool
 	HasSSSE3     bool